@@ -0,0 +1,258 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchError is returned by CommitBatched when one of its batches fails.
+// Entities committed by earlier batches, and those of the failed batch which
+// the server did create, remain committed and are marked as such, calling
+// CommitBatched again resumes with the entities left.
+type BatchError struct {
+	Batch int     // Index of the failed batch, starting at 0.
+	Err   error   // Reason the batch failed.
+	Edges []*Edge // Edges of the batch which were not created, their endpoints no longer exist.
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Edges) > 0 {
+		return fmt.Sprintf("redisgraph: commit batch %d failed: %v, %d edges were not created", e.Batch, e.Err, len(e.Edges))
+	}
+	return fmt.Sprintf("redisgraph: commit batch %d failed: %v", e.Batch, e.Err)
+}
+
+// Unwrap returns the reason the batch failed.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// commitBatch is a single query of a batched commit, creating entities by
+// unwinding a list of rows.
+type commitBatch struct {
+	query    string
+	rows     []interface{}
+	entities []interface{} // Entity created by each row.
+}
+
+// CommitBatched is like Commit but creates new nodes and edges in batches of
+// at most batchSize entities, each batch issued as its own query. Use it to
+// load graphs too large to be created by a single query.
+//
+// Nodes are created first, grouped by their labels, followed by edges grouped
+// by their relationship type, which reference their endpoints by ID. Any
+// remaining changes are then committed as by Commit. The returned result
+// holds the statistics of all batches combined, when a batch fails it is
+// returned along with a *BatchError and holds the statistics of the changes
+// applied so far.
+func (g *Graph) CommitBatched(batchSize int) (*QueryResult, error) {
+	return g.CommitBatchedContext(context.Background(), batchSize)
+}
+
+// CommitBatchedContext is like CommitBatched, honoring ctx.
+func (g *Graph) CommitBatchedContext(ctx context.Context, batchSize int) (*QueryResult, error) {
+	if batchSize < 1 {
+		return nil, fmt.Errorf("redisgraph: invalid batch size %d", batchSize)
+	}
+
+	total := newQueryResult(g)
+	batch := 0
+
+	// Edges can only be batched once their endpoints have IDs.
+	for _, build := range []func(int) []commitBatch{g.nodeBatches, g.edgeBatches} {
+		for _, b := range build(batchSize) {
+			res, missing, err := g.commitBatch(ctx, b)
+			if res != nil {
+				total.addStatistics(res)
+			}
+			if err != nil {
+				return total, &BatchError{Batch: batch, Err: err, Edges: missing}
+			}
+			batch++
+		}
+	}
+
+	res, err := g.CommitContext(ctx)
+	if err != nil {
+		return total, &BatchError{Batch: batch, Err: err}
+	}
+	total.addStatistics(res)
+	return total, nil
+}
+
+// commitBatch issues b and assigns IDs to the entities it created. Edges
+// whose endpoints no longer exist produce no row, they are returned along
+// with ErrEntityNotFound while the other entities of the batch are committed.
+func (g *Graph) commitBatch(ctx context.Context, b commitBatch) (*QueryResult, []*Edge, error) {
+	res, err := g.QueryContext(ctx, b.query, map[string]interface{}{"rows": b.rows}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created := make([]bool, len(b.entities))
+	for _, r := range res.results {
+		values := r.Values()
+		i, ok := toInt64(values[0])
+		if !ok || i < 0 || i >= int64(len(b.entities)) {
+			return nil, nil, newParseError("malformed batch row index", values[0], nil)
+		}
+		id, ok := toInt64(values[1])
+		if !ok || id < 0 {
+			return nil, nil, newParseError("malformed entity ID", values[1], nil)
+		}
+		switch entity := b.entities[i].(type) {
+		case *Node:
+			entity.ID = uint64(id)
+			entity.markCommitted()
+		case *Edge:
+			entity.ID = uint64(id)
+			entity.markCommitted()
+		}
+		created[i] = true
+	}
+
+	complete := len(res.results) == len(b.rows)
+	res.results = nil
+	res.header = newQueryResult(g).header
+	if complete {
+		return res, nil, nil
+	}
+	var missing []*Edge
+	for i, ok := range created {
+		if e, isEdge := b.entities[i].(*Edge); isEdge && !ok {
+			missing = append(missing, e)
+		}
+	}
+	return res, missing, ErrEntityNotFound
+}
+
+// nodeBatches groups the graph's new nodes by their labels, in batches of
+// at most size nodes.
+func (g *Graph) nodeBatches(size int) []commitBatch {
+	aliases := make([]string, 0, len(g.Nodes))
+	for alias, n := range g.Nodes {
+		if !n.persisted {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	var keys []string
+	groups := make(map[string][]interface{})
+	for _, alias := range aliases {
+		n := g.Nodes[alias]
+		key := strings.Join(n.Labels, ":")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], n)
+	}
+
+	var batches []commitBatch
+	for _, key := range keys {
+		labels := ""
+		if key != "" {
+			labels = ":" + key
+		}
+		batches = append(batches, splitBatches(groups[key], size, func(i int, entity interface{}) map[string]interface{} {
+			return map[string]interface{}{"i": i, "p": entity.(*Node).Properties}
+		}, func(props string) string {
+			return "UNWIND $rows AS row CREATE (n" + labels + props + ") RETURN row.i, id(n)"
+		})...)
+	}
+	return batches
+}
+
+// edgeBatches groups the graph's new edges by their relationship type, in
+// batches of at most size edges.
+func (g *Graph) edgeBatches(size int) []commitBatch {
+	var keys []string
+	groups := make(map[string][]interface{})
+	for _, e := range g.Edges {
+		if e.persisted {
+			continue
+		}
+		if _, ok := groups[e.Relation]; !ok {
+			keys = append(keys, e.Relation)
+		}
+		groups[e.Relation] = append(groups[e.Relation], e)
+	}
+
+	var batches []commitBatch
+	for _, key := range keys {
+		relation := ""
+		if key != "" {
+			relation = ":" + key
+		}
+		batches = append(batches, splitBatches(groups[key], size, func(i int, entity interface{}) map[string]interface{} {
+			e := entity.(*Edge)
+			return map[string]interface{}{"i": i, "s": e.Source.ID, "d": e.Destination.ID, "p": e.Properties}
+		}, func(props string) string {
+			return "UNWIND $rows AS row MATCH (s) WHERE id(s) = row.s MATCH (d) WHERE id(d) = row.d " +
+				"CREATE (s)-[e" + relation + props + "]->(d) RETURN row.i, id(e)"
+		})...)
+	}
+	return batches
+}
+
+// splitBatches splits entities into batches of at most size rows. row builds
+// the row of an entity given its index within its batch, its properties held
+// under "p". query builds the batch's query given the pattern of properties
+// to set, which covers every property of the batch.
+func splitBatches(entities []interface{}, size int,
+	row func(i int, entity interface{}) map[string]interface{},
+	query func(props string) string) []commitBatch {
+
+	var batches []commitBatch
+	for start := 0; start < len(entities); start += size {
+		end := start + size
+		if end > len(entities) {
+			end = len(entities)
+		}
+		chunk := entities[start:end]
+
+		// Missing properties evaluate to null and are not set.
+		seen := make(map[string]bool)
+		var keys []string
+		rows := make([]interface{}, len(chunk))
+		for i, entity := range chunk {
+			r := row(i, entity)
+			for k := range r["p"].(map[string]interface{}) {
+				if !seen[k] {
+					seen[k] = true
+					keys = append(keys, k)
+				}
+			}
+			rows[i] = r
+		}
+		sort.Strings(keys)
+
+		props := ""
+		if len(keys) > 0 {
+			p := make([]string, len(keys))
+			for i, k := range keys {
+				p[i] = fmt.Sprintf("%s: row.p.%s", quoteIdentifier(k), quoteIdentifier(k))
+			}
+			props = " {" + strings.Join(p, ", ") + "}"
+		}
+
+		batches = append(batches, commitBatch{
+			query:    query(props),
+			rows:     rows,
+			entities: chunk,
+		})
+	}
+	return batches
+}
+
+// addStatistics adds the statistics of other to those of qr.
+func (qr *QueryResult) addStatistics(other *QueryResult) {
+	if qr.statistics == nil {
+		qr.statistics = make(map[string]float64, len(other.statistics))
+	}
+	for k, v := range other.statistics {
+		qr.statistics[k] += v
+	}
+}
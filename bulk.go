@@ -0,0 +1,761 @@
+package redisgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// PropertyType is the type a CSV column is loaded as by BulkLoad.
+type PropertyType int
+
+const (
+	PROPERTY_INFERRED PropertyType = iota // Inferred from each value.
+	PROPERTY_STRING
+	PROPERTY_INTEGER
+	PROPERTY_DOUBLE
+	PROPERTY_BOOLEAN
+	PROPERTY_ARRAY
+	PROPERTY_IGNORED // The column is not loaded.
+)
+
+// Value types of the GRAPH.BULK binary format.
+const (
+	bulkNull byte = iota
+	bulkBool
+	bulkDouble
+	bulkString
+	bulkLong
+	bulkArray
+)
+
+// defaultBulkBatchSize is the default number of bytes sent by a single GRAPH.BULK command.
+const defaultBulkBatchSize = 64 << 20
+
+// BulkOptions are a set of additional arguments for BulkLoad.
+type BulkOptions struct {
+	batchSize int
+	progress  func(BulkProgress)
+}
+
+// NewBulkOptions instantiates a new BulkOptions struct.
+func NewBulkOptions() *BulkOptions {
+	return &BulkOptions{
+		batchSize: defaultBulkBatchSize,
+	}
+}
+
+// SetBatchSize sets the maximum number of bytes sent by a single GRAPH.BULK command.
+func (options *BulkOptions) SetBatchSize(size int) *BulkOptions {
+	options.batchSize = size
+	return options
+}
+
+// GetBatchSize retrieves the maximum number of bytes sent by a single GRAPH.BULK command.
+func (options *BulkOptions) GetBatchSize() int {
+	return options.batchSize
+}
+
+// SetProgress sets a function called after each batch has been loaded.
+func (options *BulkOptions) SetProgress(progress func(BulkProgress)) *BulkOptions {
+	options.progress = progress
+	return options
+}
+
+// BulkProgress reports the outcome of a single GRAPH.BULK batch.
+type BulkProgress struct {
+	Batch        int // Index of the batch, starting at 0.
+	NodesCreated int // Nodes created by the batch.
+	EdgesCreated int // Edges created by the batch.
+	TotalNodes   int // Nodes created so far.
+	TotalEdges   int // Edges created so far.
+}
+
+// BulkResult summarizes a bulk load.
+type BulkResult struct {
+	Batches      int // Number of GRAPH.BULK commands issued.
+	NodesCreated int // Nodes created by all batches.
+	EdgesCreated int // Edges created by all batches.
+}
+
+// BulkSource provides entities to BulkLoad, see BulkNodesCSV, BulkEdgesCSV,
+// BulkNodes and BulkEdges.
+type BulkSource interface {
+	edges() bool
+	load(l *bulkLoader) error
+}
+
+// BulkLoad creates a new graph out of sources using GRAPH.BULK, which is
+// considerably faster than creating entities with queries. The graph must
+// not exist. Node sources are loaded before edge sources, which reference
+// their endpoints by the nodes' identifiers.
+//
+// Entities are streamed in batches, see BulkOptions. Should a batch fail a
+// *BatchError is returned and the graph holds the batches loaded before it.
+func (g *Graph) BulkLoad(options *BulkOptions, sources ...BulkSource) (*BulkResult, error) {
+	return g.BulkLoadContext(context.Background(), options, sources...)
+}
+
+// BulkLoadContext is like BulkLoad, honoring ctx.
+func (g *Graph) BulkLoadContext(ctx context.Context, options *BulkOptions, sources ...BulkSource) (*BulkResult, error) {
+	if options == nil {
+		options = NewBulkOptions()
+	}
+	if options.batchSize < 1 {
+		return nil, fmt.Errorf("redisgraph: invalid batch size %d", options.batchSize)
+	}
+
+	l := &bulkLoader{
+		ctx:     ctx,
+		graph:   g,
+		options: options,
+		ids:     make(map[string]uint64),
+		nodes:   make(map[*Node]uint64),
+	}
+
+	// Edges can only be encoded once their endpoints have IDs, which are
+	// assigned as nodes are created.
+	for _, isEdges := range []bool{false, true} {
+		for _, s := range sources {
+			if s.edges() != isEdges {
+				continue
+			}
+			if err := s.load(l); err != nil {
+				return nil, err
+			}
+		}
+		if err := l.flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &l.result, nil
+}
+
+// bulkLoader accumulates entities into batches and issues them.
+type bulkLoader struct {
+	ctx     context.Context
+	graph   *Graph
+	options *BulkOptions
+	pending []*bulkGroup      // Groups holding entities of the current batch.
+	size    int               // Bytes held by the current batch.
+	ids     map[string]uint64 // Node IDs by CSV identifier.
+	nodes   map[*Node]uint64  // IDs of loaded nodes.
+	nodeID  uint64            // ID of the next node created.
+	edgeID  uint64            // ID of the next edge created.
+	result  BulkResult
+}
+
+// bulkGroup holds entities sharing a label, or relationship type, and a set
+// of properties. Each group is sent as its own binary blob.
+type bulkGroup struct {
+	edges  bool
+	header []byte
+	buf    bytes.Buffer
+	keys   []interface{} // Entity, or identifier, of each encoded entity.
+}
+
+func newBulkGroup(name string, props []string, edges bool) *bulkGroup {
+	var header bytes.Buffer
+	header.WriteString(name)
+	header.WriteByte(0)
+	binary.Write(&header, binary.LittleEndian, uint32(len(props)))
+	for _, p := range props {
+		header.WriteString(p)
+		header.WriteByte(0)
+	}
+	return &bulkGroup{edges: edges, header: header.Bytes()}
+}
+
+// add appends an encoded entity to g, key is either a CSV identifier, a
+// *Node, an *Edge or nil.
+func (l *bulkLoader) add(g *bulkGroup, key interface{}, entity []byte) error {
+	size := len(entity)
+	if len(g.keys) == 0 {
+		size += len(g.header)
+	}
+	if l.size > 0 && l.size+size > l.options.batchSize {
+		if err := l.flush(); err != nil {
+			return err
+		}
+		size = len(g.header) + len(entity)
+	}
+
+	if len(g.keys) == 0 {
+		l.pending = append(l.pending, g)
+	}
+	g.buf.Write(entity)
+	g.keys = append(g.keys, key)
+	l.size += size
+	return nil
+}
+
+// flush issues the current batch.
+func (l *bulkLoader) flush() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+
+	var nodes, edges, labels, relations int
+	for _, g := range l.pending {
+		if g.edges {
+			edges += len(g.keys)
+			relations++
+		} else {
+			nodes += len(g.keys)
+			labels++
+		}
+	}
+
+	args := []interface{}{l.graph.Id}
+	if l.result.Batches == 0 {
+		args = append(args, "BEGIN")
+	}
+	args = append(args, nodes, edges, labels, relations)
+	// Label blobs precede relationship type blobs.
+	for _, isEdges := range []bool{false, true} {
+		for _, g := range l.pending {
+			if g.edges == isEdges {
+				blob := make([]byte, 0, len(g.header)+g.buf.Len())
+				args = append(args, append(append(blob, g.header...), g.buf.Bytes()...))
+			}
+		}
+	}
+
+	batch := l.result.Batches
+	r, err := l.graph.do(l.ctx, "GRAPH.BULK", args...)
+	if err == nil {
+		nodes, edges, err = parseBulkReply(r)
+	}
+	if err != nil {
+		return &BatchError{Batch: batch, Err: err}
+	}
+
+	// The server assigns IDs sequentially, in the order entities were sent.
+	for _, isEdges := range []bool{false, true} {
+		for _, g := range l.pending {
+			if g.edges != isEdges {
+				continue
+			}
+			for _, key := range g.keys {
+				l.assignID(key)
+			}
+			g.buf.Reset()
+			g.keys = g.keys[:0]
+		}
+	}
+	l.pending = l.pending[:0]
+	l.size = 0
+
+	l.result.Batches++
+	l.result.NodesCreated += nodes
+	l.result.EdgesCreated += edges
+	if l.options.progress != nil {
+		l.options.progress(BulkProgress{
+			Batch:        batch,
+			NodesCreated: nodes,
+			EdgesCreated: edges,
+			TotalNodes:   l.result.NodesCreated,
+			TotalEdges:   l.result.EdgesCreated,
+		})
+	}
+	return nil
+}
+
+func (l *bulkLoader) assignID(key interface{}) {
+	switch k := key.(type) {
+	case string:
+		l.ids[k] = l.nodeID
+		l.nodeID++
+	case *Node:
+		k.ID = l.nodeID
+		k.markCommitted()
+		l.nodes[k] = l.nodeID
+		l.nodeID++
+	case *Edge:
+		k.ID = l.edgeID
+		k.markCommitted()
+		l.edgeID++
+	case nil:
+		// An edge loaded from CSV.
+		l.edgeID++
+	}
+}
+
+// parseBulkReply parses a "N nodes created, M edges created" reply.
+func parseBulkReply(reply interface{}) (nodes int, edges int, err error) {
+	s, err := redis.String(reply, nil)
+	if err != nil {
+		return 0, 0, newParseError("malformed bulk reply", reply, err)
+	}
+	parts := strings.Split(s, ", ")
+	if len(parts) != 2 {
+		return 0, 0, newParseError("malformed bulk reply", reply, nil)
+	}
+	counts := make([]int, 2)
+	for i, p := range parts {
+		fields := strings.Fields(p)
+		if len(fields) == 0 {
+			return 0, 0, newParseError("malformed bulk reply", reply, nil)
+		}
+		if counts[i], err = strconv.Atoi(fields[0]); err != nil {
+			return 0, 0, newParseError("malformed bulk reply", reply, err)
+		}
+	}
+	return counts[0], counts[1], nil
+}
+
+type csvSource struct {
+	name    string
+	r       io.Reader
+	schema  map[string]PropertyType
+	isEdges bool
+}
+
+// BulkNodesCSV loads nodes labeled label from CSV. The first row names the
+// columns, each of which is loaded as a property. The first column identifies
+// nodes for edge sources to reference, identifiers must be unique across all
+// node sources.
+//
+// Column types are taken from schema, keyed by column name, and inferred from
+// each value otherwise. Empty values are loaded as null, i.e. the property is
+// not set.
+func BulkNodesCSV(label string, r io.Reader, schema map[string]PropertyType) BulkSource {
+	return &csvSource{name: label, r: r, schema: schema}
+}
+
+// BulkEdgesCSV loads edges of type relation from CSV. The first row names the
+// columns, the first two of which hold the identifiers of the edge's source
+// and destination nodes while the remaining columns are loaded as properties,
+// see BulkNodesCSV.
+func BulkEdgesCSV(relation string, r io.Reader, schema map[string]PropertyType) BulkSource {
+	return &csvSource{name: relation, r: r, schema: schema, isEdges: true}
+}
+
+func (s *csvSource) edges() bool {
+	return s.isEdges
+}
+
+func (s *csvSource) load(l *bulkLoader) error {
+	cr := csv.NewReader(s.r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("redisgraph: %s: reading CSV header: %v", s.name, err)
+	}
+	for column := range s.schema {
+		if !containsString(header, column) {
+			return fmt.Errorf("redisgraph: %s: schema column %q not found", s.name, column)
+		}
+	}
+
+	// Edges start with their endpoints' identifiers.
+	start, required := 0, 1
+	if s.isEdges {
+		start, required = 2, 2
+	}
+	if len(header) < required {
+		return fmt.Errorf("redisgraph: %s: CSV header has too few columns", s.name)
+	}
+
+	var props []string
+	var columns []int
+	for i := start; i < len(header); i++ {
+		if s.schema[header[i]] != PROPERTY_IGNORED {
+			props = append(props, header[i])
+			columns = append(columns, i)
+		}
+	}
+	g := newBulkGroup(s.name, props, s.isEdges)
+
+	var buf bytes.Buffer
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("redisgraph: %s: %v", s.name, err)
+		}
+
+		buf.Reset()
+		var key interface{}
+		if s.isEdges {
+			for _, id := range record[:2] {
+				nodeID, ok := l.ids[id]
+				if !ok {
+					return fmt.Errorf("redisgraph: %s line %d: unknown node %q", s.name, line, id)
+				}
+				binary.Write(&buf, binary.LittleEndian, nodeID)
+			}
+		} else {
+			if _, ok := l.ids[record[0]]; ok {
+				return fmt.Errorf("redisgraph: %s line %d: duplicate node %q", s.name, line, record[0])
+			}
+			// Reserved until the node is created and its ID known.
+			l.ids[record[0]] = math.MaxUint64
+			key = record[0]
+		}
+
+		for _, c := range columns {
+			if err := writeBulkCSVValue(&buf, record[c], s.schema[header[c]]); err != nil {
+				return fmt.Errorf("redisgraph: %s line %d column %q: %v", s.name, line, header[c], err)
+			}
+		}
+		if err := l.add(g, key, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+type nodesSource []*Node
+
+// BulkNodes loads nodes, every node must have at least one label. Loaded
+// nodes are assigned their IDs and can be referenced by BulkEdges sources.
+func BulkNodes(nodes []*Node) BulkSource {
+	return nodesSource(nodes)
+}
+
+func (s nodesSource) edges() bool {
+	return false
+}
+
+func (s nodesSource) load(l *bulkLoader) error {
+	var names []string
+	groups := make(map[string][]interface{})
+	for _, n := range s {
+		if len(n.Labels) == 0 {
+			return fmt.Errorf("redisgraph: bulk loaded nodes require a label")
+		}
+		if _, ok := l.nodes[n]; ok {
+			return fmt.Errorf("redisgraph: node %s loaded twice", n)
+		}
+		l.nodes[n] = math.MaxUint64
+		name := strings.Join(n.Labels, ":")
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], n)
+	}
+
+	for _, name := range names {
+		err := loadEntities(l, name, false, groups[name], func(buf *bytes.Buffer, entity interface{}) (map[string]interface{}, error) {
+			return entity.(*Node).Properties, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type edgesSource []*Edge
+
+// BulkEdges loads edges, whose endpoints must have been loaded by a BulkNodes
+// source. Loaded edges are assigned their IDs.
+func BulkEdges(edges []*Edge) BulkSource {
+	return edgesSource(edges)
+}
+
+func (s edgesSource) edges() bool {
+	return true
+}
+
+func (s edgesSource) load(l *bulkLoader) error {
+	var names []string
+	groups := make(map[string][]interface{})
+	for _, e := range s {
+		if e.Relation == "" {
+			return fmt.Errorf("redisgraph: bulk loaded edges require a relationship type")
+		}
+		if _, ok := groups[e.Relation]; !ok {
+			names = append(names, e.Relation)
+		}
+		groups[e.Relation] = append(groups[e.Relation], e)
+	}
+
+	for _, name := range names {
+		err := loadEntities(l, name, true, groups[name], func(buf *bytes.Buffer, entity interface{}) (map[string]interface{}, error) {
+			e := entity.(*Edge)
+			for _, n := range []*Node{e.Source, e.Destination} {
+				id, ok := l.nodes[n]
+				if !ok {
+					return nil, fmt.Errorf("redisgraph: edge %s references a node which has not been loaded", e.Relation)
+				}
+				binary.Write(buf, binary.LittleEndian, id)
+			}
+			return e.Properties, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEntities adds entities sharing a label or relationship type to a single
+// group, covering all of their properties. prefix writes anything preceding
+// an entity's properties and returns them.
+func loadEntities(l *bulkLoader, name string, edges bool, entities []interface{},
+	prefix func(buf *bytes.Buffer, entity interface{}) (map[string]interface{}, error)) error {
+
+	seen := make(map[string]bool)
+	var props []string
+	for _, entity := range entities {
+		var p map[string]interface{}
+		switch e := entity.(type) {
+		case *Node:
+			p = e.Properties
+		case *Edge:
+			p = e.Properties
+		}
+		for k := range p {
+			if !seen[k] {
+				seen[k] = true
+				props = append(props, k)
+			}
+		}
+	}
+	sort.Strings(props)
+	g := newBulkGroup(name, props, edges)
+
+	var buf bytes.Buffer
+	for _, entity := range entities {
+		buf.Reset()
+		values, err := prefix(&buf, entity)
+		if err != nil {
+			return err
+		}
+		for _, k := range props {
+			// Missing properties are sent as null, which leaves them unset.
+			if err := writeBulkValue(&buf, reflect.ValueOf(values[k])); err != nil {
+				return err
+			}
+		}
+		if err := l.add(g, entity, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBulkValue encodes v in the GRAPH.BULK binary format.
+func writeBulkValue(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteByte(bulkNull)
+		return nil
+	}
+
+	if k := v.Kind(); k != reflect.Ptr && k != reflect.Interface &&
+		(v.Type().Implements(cypherValueType) || v.Type() == durationType) {
+		return &UnsupportedValueError{Value: v.Interface(), Msg: "not supported by bulk loading"}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteByte(bulkNull)
+			return nil
+		}
+		return writeBulkValue(buf, v.Elem())
+
+	case reflect.Bool:
+		buf.WriteByte(bulkBool)
+		if v.Bool() {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+
+	case reflect.String:
+		return writeBulkString(buf, v.String())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteByte(bulkLong)
+		binary.Write(buf, binary.LittleEndian, v.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > math.MaxInt64 {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: "integer overflows int64"}
+		}
+		buf.WriteByte(bulkLong)
+		binary.Write(buf, binary.LittleEndian, int64(u))
+
+	case reflect.Float32, reflect.Float64:
+		buf.WriteByte(bulkDouble)
+		binary.Write(buf, binary.LittleEndian, v.Float())
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteByte(bulkNull)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return writeBulkString(buf, string(b))
+		}
+		buf.WriteByte(bulkArray)
+		binary.Write(buf, binary.LittleEndian, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := writeBulkValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return &UnsupportedValueError{Value: v.Interface(), Msg: "not supported by bulk loading"}
+	}
+
+	return nil
+}
+
+func writeBulkString(buf *bytes.Buffer, s string) error {
+	if strings.IndexByte(s, 0) >= 0 || !utf8.ValidString(s) {
+		return &UnsupportedValueError{Value: s, Msg: "strings must be valid UTF-8 without NUL bytes"}
+	}
+	buf.WriteByte(bulkString)
+	buf.WriteString(s)
+	buf.WriteByte(0)
+	return nil
+}
+
+// writeBulkCSVValue encodes CSV value s as type t.
+func writeBulkCSVValue(buf *bytes.Buffer, s string, t PropertyType) error {
+	if s == "" {
+		buf.WriteByte(bulkNull)
+		return nil
+	}
+
+	var v interface{}
+	var err error
+	switch t {
+	case PROPERTY_INFERRED:
+		v = inferCSVValue(s)
+	case PROPERTY_STRING:
+		v = s
+	case PROPERTY_INTEGER:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case PROPERTY_DOUBLE:
+		v, err = strconv.ParseFloat(s, 64)
+	case PROPERTY_BOOLEAN:
+		v, err = strconv.ParseBool(s)
+	case PROPERTY_ARRAY:
+		v, err = parseCSVArray(s)
+	default:
+		err = fmt.Errorf("unknown property type %d", t)
+	}
+	if err != nil {
+		return err
+	}
+	return writeBulkValue(buf, reflect.ValueOf(v))
+}
+
+// inferCSVValue converts s to a boolean, integer, double or array when it
+// holds one, and leaves it a string otherwise.
+func inferCSVValue(s string) interface{} {
+	switch strings.ToLower(s) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		if a, err := parseCSVArray(s); err == nil {
+			return a
+		}
+	}
+	return s
+}
+
+// parseCSVArray parses an array literal such as [1, 'a', [true]], elements
+// which are not quoted strings or arrays are inferred.
+func parseCSVArray(s string) ([]interface{}, error) {
+	v, rest, err := parseCSVArrayValue(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.([]interface{})
+	if !ok || strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("malformed array %q", s)
+	}
+	return a, nil
+}
+
+// parseCSVArrayValue parses the value at the start of s, returning it along
+// with the remainder of s.
+func parseCSVArrayValue(s string) (interface{}, string, error) {
+	switch {
+	case s == "":
+		return nil, "", fmt.Errorf("missing array element")
+
+	case s[0] == '[':
+		a := []interface{}{}
+		rest := strings.TrimSpace(s[1:])
+		if strings.HasPrefix(rest, "]") {
+			return a, rest[1:], nil
+		}
+		for {
+			v, r, err := parseCSVArrayValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			a = append(a, v)
+			r = strings.TrimSpace(r)
+			switch {
+			case strings.HasPrefix(r, ","):
+				rest = strings.TrimSpace(r[1:])
+			case strings.HasPrefix(r, "]"):
+				return a, r[1:], nil
+			default:
+				return nil, "", fmt.Errorf("unterminated array")
+			}
+		}
+
+	case s[0] == '\'' || s[0] == '"':
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case s[0]:
+				return sb.String(), s[i+1:], nil
+			case '\\':
+				if i+1 < len(s) {
+					i++
+				}
+			}
+			sb.WriteByte(s[i])
+		}
+		return nil, "", fmt.Errorf("unterminated string")
+
+	default:
+		end := strings.IndexAny(s, ",]")
+		if end < 0 {
+			end = len(s)
+		}
+		token := strings.TrimSpace(s[:end])
+		if token == "" {
+			return nil, "", fmt.Errorf("missing array element")
+		}
+		if strings.EqualFold(token, "null") {
+			return nil, s[end:], nil
+		}
+		return inferCSVValue(token), s[end:], nil
+	}
+}
@@ -0,0 +1,157 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Client manages the graphs stored on a server. Graph handles opened through
+// a client share its connection provider.
+type Client struct {
+	pool   ConnProvider
+	mutex  sync.Mutex
+	graphs map[string]*Graph
+	retry  *RetryPolicy
+}
+
+// ClientNew creates a new client, drawing connections from pool.
+func ClientNew(pool ConnProvider) *Client {
+	return &Client{
+		pool:   pool,
+		graphs: make(map[string]*Graph),
+	}
+}
+
+// Graph returns a handle to the graph named name, the handle is cached so that
+// repeated calls share the graph's schema mappings. The graph is not required
+// to exist, it is created by the first query writing to it.
+func (c *Client) Graph(name string) *Graph {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	g, ok := c.graphs[name]
+	if !ok {
+		graph := GraphNewWithPool(name, c.pool)
+		graph.SetRetryPolicy(c.retry)
+		g = &graph
+		c.graphs[name] = g
+	}
+	return g
+}
+
+// SetRetryPolicy sets the retry policy of the graph handles returned by Graph,
+// see Graph.SetRetryPolicy. It must not be called while any of them is in use.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.retry = policy
+	for _, g := range c.graphs {
+		g.SetRetryPolicy(policy)
+	}
+}
+
+// do issues a single command over a connection borrowed from the client's pool.
+func (c *Client) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	conn, err := getProviderConn(ctx, c.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return doContext(ctx, conn, cmd, args...)
+}
+
+// List returns the names of all graphs stored on the server, or on every
+// master of a Cluster, sorted.
+func (c *Client) List() ([]string, error) {
+	return c.ListContext(context.Background())
+}
+
+// ListContext returns the names of all graphs stored on the server, honoring ctx.
+func (c *Client) ListContext(ctx context.Context) ([]string, error) {
+	r, err := c.do(ctx, "GRAPH.LIST")
+	if err != nil {
+		return nil, err
+	}
+	names, err := redis.Strings(r, nil)
+	if err != nil {
+		return nil, newParseError("malformed graph list", r, err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Copy creates graph dst as a copy of graph src, dst must not exist. Over a
+// Cluster, both names must hash to the same slot, see Slot.
+func (c *Client) Copy(src, dst string) error {
+	return c.CopyContext(context.Background(), src, dst)
+}
+
+// CopyContext creates graph dst as a copy of graph src, honoring ctx.
+func (c *Client) CopyContext(ctx context.Context, src, dst string) error {
+	_, err := c.do(ctx, "GRAPH.COPY", src, dst)
+	return err
+}
+
+// Delete removes the named graphs and all of their entities. Every graph is
+// attempted, graphs which fail to be removed are reported by a *DeleteError.
+func (c *Client) Delete(names ...string) error {
+	return c.DeleteContext(context.Background(), names...)
+}
+
+// DeleteContext removes the named graphs, honoring ctx.
+func (c *Client) DeleteContext(ctx context.Context, names ...string) error {
+	var failed map[string]error
+	for _, name := range names {
+		c.mutex.Lock()
+		g, cached := c.graphs[name]
+		c.mutex.Unlock()
+
+		var err error
+		if cached {
+			// Resets the handle's schema mappings as well.
+			err = g.DeleteContext(ctx)
+		} else {
+			_, err = c.do(ctx, "GRAPH.DELETE", name)
+		}
+		if err != nil {
+			if failed == nil {
+				failed = make(map[string]error)
+			}
+			failed[name] = err
+		}
+	}
+
+	if failed != nil {
+		return &DeleteError{Errors: failed}
+	}
+	return nil
+}
+
+// DeleteError is returned by Client.Delete when some graphs could not be removed.
+type DeleteError struct {
+	Errors map[string]error // Failure reason, keyed by graph name.
+}
+
+func (e *DeleteError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("%s: %v", name, e.Errors[name])
+	}
+	return "redisgraph: failed to delete graphs: " + strings.Join(msgs, "; ")
+}
@@ -1,8 +1,21 @@
 package redisgraph
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/assert"
@@ -518,3 +531,1919 @@ func TestTimeout(t *testing.T) {
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
 }
+
+func TestGraphNewWithPool(t *testing.T) {
+	createGraph()
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", "0.0.0.0:6379")
+	}}
+	defer pool.Close()
+
+	pooled := GraphNewWithPool("social", pool)
+
+	// Issue queries concurrently, all sharing the same graph handle.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := pooled.ROQuery("MATCH (s)-[e]->(d) RETURN s,e,d")
+			assert.Nil(t, err)
+			checkQueryResults(t, res)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0, pool.ActiveCount(), "Expecting all connections to be returned to the pool")
+}
+
+func TestQueryContext(t *testing.T) {
+	createGraph()
+
+	params := map[string]interface{}{"name": "John Doe"}
+	res, err := graph.QueryContext(context.Background(), "MATCH (p:Person {name: $name}) RETURN p.age", params, nil)
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, 33, res.Record().GetByIndex(0), "Unexpected property value.")
+
+	res, err = graph.ROQueryContext(context.Background(), "MATCH (s)-[e]->(d) RETURN s,e,d", nil, nil)
+	assert.Nil(t, err)
+	checkQueryResults(t, res)
+
+	res, err = graph.CallProcedureContext(context.Background(), "db.labels", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res.results), "Expecting 2 labels")
+
+	// A cancelled context must prevent the query from being issued.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = graph.QueryContext(ctx, "MATCH (n) RETURN n", nil, nil)
+	assert.Nil(t, res)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = graph.ExecutionPlanContext(ctx, "MATCH (n) RETURN n")
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestQueryContextDeadlineTimeout(t *testing.T) {
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", "0.0.0.0:6379")
+	}}
+	defer pool.Close()
+	pooled := GraphNewWithPool("social", pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Without an explicit timeout, the deadline is sent as the server-side timeout.
+	options := NewQueryOptions().SetTimeoutFromContext(true)
+	assert.True(t, options.GetTimeoutFromContext())
+	timeout := options.effectiveTimeout(ctx)
+	assert.True(t, timeout > 9000 && timeout <= 10000, "Expecting timeout to be derived from deadline")
+
+	// An explicit timeout shorter than the deadline takes precedence.
+	options.SetTimeout(1)
+	assert.Equal(t, 1, options.effectiveTimeout(ctx))
+
+	res, err := pooled.ROQueryContext(ctx, "UNWIND range(0, 1000000) AS v RETURN v", nil, options)
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+}
+
+func TestQueryResultParseErrors(t *testing.T) {
+	g := GraphNew("parse_errors", nil)
+
+	// Statistics only reply.
+	res, err := QueryResultNew(&g, []interface{}{[]interface{}{[]byte("Nodes created: 1")}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res.NodesCreated())
+
+	header := []interface{}{[]interface{}{int64(COLUMN_SCALAR), []byte("x")}}
+	stats := []interface{}{[]byte("Query internal execution time: 0.1 milliseconds")}
+
+	replies := map[string]interface{}{
+		"not an array":      []byte("OK"),
+		"empty reply":       []interface{}{},
+		"malformed stat":    []interface{}{[]interface{}{[]byte("garbage")}},
+		"unknown scalar":    []interface{}{header, []interface{}{[]interface{}{[]interface{}{int64(99), []byte("?")}}}, stats},
+		"malformed integer": []interface{}{header, []interface{}{[]interface{}{[]interface{}{int64(VALUE_INTEGER), []byte("abc")}}}, stats},
+		"short record":      []interface{}{header, []interface{}{[]interface{}{}}, stats},
+		"unknown column":    []interface{}{[]interface{}{[]interface{}{int64(42), []byte("x")}}, []interface{}{[]interface{}{[]byte("?")}}, stats},
+		"malformed path":    []interface{}{header, []interface{}{[]interface{}{[]interface{}{int64(VALUE_PATH), []byte("?")}}}, stats},
+		"malformed map":     []interface{}{header, []interface{}{[]interface{}{[]interface{}{int64(VALUE_MAP), []interface{}{[]byte("k")}}}}, stats},
+		"malformed array":   []interface{}{header, []interface{}{[]interface{}{[]interface{}{int64(VALUE_ARRAY), []interface{}{[]byte("?")}}}}, stats},
+	}
+
+	for name, reply := range replies {
+		res, err := QueryResultNew(&g, reply)
+		assert.Nil(t, res, name)
+		_, ok := err.(*ParseError)
+		assert.True(t, ok, "%s: expecting a ParseError, got %v", name, err)
+	}
+
+	// Run-time errors are reported as is.
+	_, err = QueryResultNew(&g, []interface{}{redis.Error("boom")})
+	assert.Equal(t, redis.Error("boom"), err)
+}
+
+func TestScan(t *testing.T) {
+	createGraph()
+
+	type country struct {
+		Name       string `redisgraph:"name"`
+		Population int64  `redisgraph:"population"`
+	}
+	type visit struct {
+		Name    string  `redisgraph:"p.name"`
+		Age     float64 `redisgraph:"p.age"`
+		Year    string  `redisgraph:"year"`
+		Country country `redisgraph:"c"`
+	}
+
+	res, err := graph.Query("MATCH (p:Person)-[v:Visited]->(c:Country) RETURN p.name, p.age, v.year AS year, c")
+	assert.Nil(t, err)
+
+	var visits []visit
+	err = res.ScanAll(&visits)
+	assert.Nil(t, err)
+	assert.Equal(t, []visit{{"John Doe", 33, "2017", country{"Japan", 126800000}}}, visits)
+
+	// Positional destinations.
+	res.Next()
+	var name string
+	var age int8
+	var year *int
+	var c *Node
+	err = res.Record().Scan(&name, &age, &year, &c)
+	assert.Nil(t, err)
+	assert.Equal(t, "John Doe", name)
+	assert.Equal(t, int8(33), age)
+	assert.Equal(t, 2017, *year)
+	assert.Equal(t, "Japan", c.GetProperty("name"))
+
+	// A single node column decodes into a struct.
+	res, err = graph.Query("MATCH (c:Country) RETURN c")
+	assert.Nil(t, err)
+	var countries []*country
+	err = res.ScanAll(&countries)
+	assert.Nil(t, err)
+	assert.Equal(t, []*country{{"Japan", 126800000}}, countries)
+
+	// Conversion failures name the column and the expected type.
+	res.Next()
+	var wrong struct {
+		Name bool `redisgraph:"name"`
+	}
+	err = res.Record().Scan(&wrong)
+	scanErr, ok := err.(*ScanError)
+	assert.True(t, ok, "Expecting a ScanError")
+	assert.Equal(t, "c", scanErr.Column)
+	assert.Equal(t, "Name", scanErr.Field)
+	assert.Equal(t, "bool", scanErr.Type.String())
+
+	var small int8
+	err = res.Record().Scan(&small)
+	assert.NotNil(t, err)
+}
+
+func TestScanValueTypes(t *testing.T) {
+	at := time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)
+	loc := Point{Latitude: 32.5, Longitude: -117}
+	vec := Vector32{1, 2.5}
+	keys := []string{"v"}
+
+	var tm time.Time
+	assert.Nil(t, recordNew([]interface{}{at}, keys, columnIndex(keys)).Scan(&tm))
+	assert.Equal(t, at, tm)
+
+	var p Point
+	assert.Nil(t, recordNew([]interface{}{loc}, keys, columnIndex(keys)).Scan(&p))
+	assert.Equal(t, loc, p)
+
+	var v Vector32
+	assert.Nil(t, recordNew([]interface{}{vec}, keys, columnIndex(keys)).Scan(&v))
+	assert.Equal(t, vec, v)
+
+	err := recordNew([]interface{}{"not a time"}, keys, columnIndex(keys)).Scan(&tm)
+	_, ok := err.(*ScanError)
+	assert.True(t, ok, "Expecting a ScanError")
+
+	qr := newQueryResult(nil)
+	qr.header.column_names = keys
+	qr.header.column_index = columnIndex(keys)
+	qr.results = []*Record{
+		recordNew([]interface{}{at}, keys, qr.header.column_index),
+		recordNew([]interface{}{at.Add(time.Hour)}, keys, qr.header.column_index),
+	}
+	var times []time.Time
+	assert.Nil(t, qr.ScanAll(&times))
+	assert.Equal(t, []time.Time{at, at.Add(time.Hour)}, times)
+
+	qr.results = []*Record{recordNew([]interface{}{loc}, keys, qr.header.column_index)}
+	var points []*Point
+	assert.Nil(t, qr.ScanAll(&points))
+	assert.Equal(t, []*Point{&loc}, points)
+
+	// A struct sharing no field with the columns is reported.
+	var unrelated struct{ Name string }
+	assert.NotNil(t, recordNew([]interface{}{1}, []string{"count"}, columnIndex([]string{"count"})).Scan(&unrelated))
+}
+
+type cypherPoint struct {
+	lat, lon float64
+}
+
+func (p cypherPoint) EncodeCypher() (string, error) {
+	return fmt.Sprintf("point({latitude: %v, longitude: %v})", p.lat, p.lon), nil
+}
+
+func TestEncodeValue(t *testing.T) {
+	n := 7
+	var nilPtr *int
+	values := map[interface{}]string{
+		int8(-8):              "-8",
+		int16(16):             "16",
+		int32(32):             "32",
+		int64(math.MaxInt64):  "9223372036854775807",
+		uint(1):               "1",
+		uint8(8):              "8",
+		uint64(math.MaxInt64): "9223372036854775807",
+		float32(0.1):          "0.1",
+		2.0:                   "2.0",
+		1e300:                 "1.0e300",
+		"a\"b\n":              `"a\"b\n"`,
+		&n:                    "7",
+		nilPtr:                "null",
+		cypherPoint{1, 2}:     "point({latitude: 1, longitude: 2})",
+	}
+	for v, expected := range values {
+		s, err := EncodeValue(v)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, s, "Unexpected encoding of %T", v)
+	}
+
+	s, err := EncodeValue([]int64{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "[1,2]", s)
+
+	s, err = EncodeValue([]byte("bytes"))
+	assert.Nil(t, err)
+	assert.Equal(t, `"bytes"`, s)
+
+	s, err = EncodeValue(map[string]float64{"b": 2.5, "a": 1, "c d": 0})
+	assert.Nil(t, err)
+	assert.Equal(t, "{a: 1.0,b: 2.5,`c d`: 0.0}", s)
+
+	s, err = EncodeValue(struct {
+		Name string `redisgraph:"name"`
+		Tags []string
+	}{"x", []string{"y"}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{Tags: ["y"],name: "x"}`, s)
+
+	for _, v := range []interface{}{uint64(math.MaxUint64), math.NaN(), make(chan int), map[int]int{}} {
+		_, err = EncodeValue(v)
+		_, ok := err.(*UnsupportedValueError)
+		assert.True(t, ok, "Expecting %T to be unsupported", v)
+	}
+
+	// Parameters are emitted in a deterministic order.
+	params := map[string]interface{}{"c": 3, "a": 1, "b": []string{"x"}}
+	for i := 0; i < 10; i++ {
+		header, err := EncodeParamsHeader(params)
+		assert.Nil(t, err)
+		assert.Equal(t, `CYPHER a=1 b=["x"] c=3 `, header)
+	}
+
+	_, err = EncodeParamsHeader(map[string]interface{}{"not valid": 1})
+	assert.NotNil(t, err)
+
+	// The former signature remains available.
+	assert.Equal(t, `CYPHER a=1 b=["x"] c=3 `, BuildParamsHeader(params))
+
+	// Deprecated functions fall back to fmt.Sprint for unencodable values,
+	// the others report an error.
+	bad := map[string]interface{}{"c": map[int]string{1: "x"}}
+	node := NodeNew([]string{"Person"}, "n", bad)
+	e := EdgeNew("Knows", node, node, bad)
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "{c:map[1:x]}", node.String())
+		assert.Equal(t, "{c:map[1:x]}", e.String())
+		assert.Equal(t, "(n:Person{c:map[1:x]})", node.Encode())
+		assert.Equal(t, "(n)-[:Knows{c:map[1:x]}]->(n)", e.Encode())
+		assert.Equal(t, "map[1:x]", ToString(bad["c"]))
+		assert.Equal(t, "CYPHER a=1 c=map[1:x] ", BuildParamsHeader(map[string]interface{}{"a": 1, "c": bad["c"]}))
+	})
+	_, err = node.EncodePattern()
+	assert.IsType(t, &UnsupportedValueError{}, err)
+	_, err = e.EncodePattern()
+	assert.IsType(t, &UnsupportedValueError{}, err)
+	s, err = NodeNew([]string{"Person"}, "n", map[string]interface{}{"k": 1}).EncodePattern()
+	assert.Nil(t, err)
+	assert.Equal(t, "(n:Person{k:1})", s)
+}
+
+func TestParameterizedQueryTypes(t *testing.T) {
+	createGraph()
+	params := map[string]interface{}{
+		"i64":   int64(math.MaxInt64),
+		"u32":   uint32(math.MaxUint32),
+		"f32":   float32(1.5),
+		"whole": 3.0,
+		"ints":  []int32{1, 2},
+		"props": map[string]string{"k": "v"},
+		"nil":   nil,
+	}
+	res, err := graph.ParameterizedQuery("RETURN $u32, $f32, $whole, $ints, $props, $nil", params)
+	assert.Nil(t, err)
+	res.Next()
+	r := res.Record()
+	assert.Equal(t, math.MaxUint32, r.GetByIndex(0))
+	assert.Equal(t, 1.5, r.GetByIndex(1))
+	assert.Equal(t, 3.0, r.GetByIndex(2))
+	assert.Equal(t, []interface{}{1, 2}, r.GetByIndex(3))
+	assert.Equal(t, map[string]interface{}{"k": "v"}, r.GetByIndex(4))
+	assert.Nil(t, r.GetByIndex(5))
+
+	_, err = graph.ParameterizedQuery("RETURN $p", map[string]interface{}{"p": make(chan int)})
+	assert.NotNil(t, err)
+
+	// Procedure arguments are encoded as values.
+	_, err = graph.CallProcedure("db.idx.fulltext.createNodeIndex", nil, "Person", "name")
+	assert.Nil(t, err)
+	res, err = graph.CallProcedure("db.idx.fulltext.queryNodes", []string{"node"}, "Person", "John")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res.results), "Expecting a single node to match")
+}
+
+func TestExecutionPlanNew(t *testing.T) {
+	lines := []string{
+		"Results | Records produced: 1, Execution time: 0.001000 ms",
+		"    Project | Records produced: 1, Execution time: 0.002000 ms",
+		"        Conditional Traverse | (p)->(c:Country) | Records produced: 1, Execution time: 0.003000 ms",
+		"            Node By Index Scan | (p:Person) | Records produced: 1, Execution time: 0.004000 ms",
+	}
+	plan, err := ExecutionPlanNew(lines)
+	assert.Nil(t, err)
+	assert.True(t, plan.Profiled)
+	assert.Equal(t, "Results", plan.Root.Name)
+	assert.Equal(t, 1, len(plan.Root.Children))
+
+	scans := plan.Find("Node By Index Scan")
+	assert.Equal(t, 1, len(scans))
+	assert.Equal(t, []string{"(p:Person)"}, scans[0].Args)
+	assert.Equal(t, 1, scans[0].RecordsProduced)
+	assert.Equal(t, 0.004, scans[0].ExecutionTime)
+	assert.False(t, plan.Contains("Node By Label Scan"))
+	assert.Equal(t, strings.Join(lines, "\n")+"\n", plan.String())
+
+	_, err = ExecutionPlanNew([]string{"Results", "        Project"})
+	assert.NotNil(t, err)
+	_, err = ExecutionPlanNew(nil)
+	assert.NotNil(t, err)
+}
+
+func TestProfile(t *testing.T) {
+	createGraph()
+	_, err := graph.Query("CREATE INDEX ON :Person(name)")
+	assert.Nil(t, err)
+
+	q := "MATCH (p:Person) WHERE p.name = $name RETURN p"
+	params := map[string]interface{}{"name": "John Doe"}
+
+	plan, err := graph.Explain(q, params)
+	assert.Nil(t, err)
+	assert.False(t, plan.Profiled)
+	assert.True(t, plan.Contains("Node By Index Scan"), "Expecting an index scan:\n%s", plan)
+
+	plan, err = graph.Profile(q, params)
+	assert.Nil(t, err)
+	assert.True(t, plan.Profiled)
+	assert.Equal(t, "Results", plan.Root.Name)
+	assert.Equal(t, 1, plan.Root.RecordsProduced)
+	assert.True(t, plan.Contains("Node By Index Scan"), "Expecting an index scan:\n%s", plan)
+	assert.False(t, plan.Contains("Node By Label Scan"))
+
+	s, err := graph.ExecutionPlan(q)
+	assert.Nil(t, err)
+	assert.Contains(t, s, "Node By Index Scan")
+
+	_, err = graph.Query("DROP INDEX ON :Person(name)")
+	assert.Nil(t, err)
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	a := NormalizeQuery(`CYPHER name="John" ids=[1,2] MATCH (p:Person)  WHERE p.name = $name AND p.age > 30.5 RETURN p, 'x'`)
+	b := NormalizeQuery("CYPHER name=\"Jane\" ids=[3] MATCH (p:Person)\n WHERE p.name = $name AND p.age > 12 RETURN p, \"y\"")
+	assert.Equal(t, "MATCH (p:Person) WHERE p.name = $name AND p.age > ? RETURN p, ?", a)
+	assert.Equal(t, a, b)
+
+	assert.Equal(t, "MATCH (n:`Label 1`) WHERE id(n) IN [?] AND n.v2 = ? RETURN n", NormalizeQuery("MATCH (n:`Label 1`) WHERE id(n) IN [1, 2, 3] AND n.v2 = 'a' RETURN n"))
+
+	entries := []SlowLogEntry{
+		{Query: "MATCH (n) WHERE n.v = 1 RETURN n", Duration: 3 * time.Millisecond, Timestamp: time.Unix(10, 0)},
+		{Query: "MATCH (n) RETURN count(n)", Duration: 10 * time.Millisecond, Timestamp: time.Unix(11, 0)},
+		{Query: "MATCH (n) WHERE n.v = 2 RETURN n", Duration: 1 * time.Millisecond, Timestamp: time.Unix(12, 0)},
+	}
+	groups := GroupSlowLog(entries)
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, "MATCH (n) RETURN count(n)", groups[0].Query)
+	assert.Equal(t, "MATCH (n) WHERE n.v = ? RETURN n", groups[1].Query)
+	assert.Equal(t, 2, groups[1].Count)
+	assert.Equal(t, 4*time.Millisecond, groups[1].Total)
+	assert.Equal(t, 3*time.Millisecond, groups[1].Max)
+	assert.Equal(t, 2*time.Millisecond, groups[1].Mean())
+	assert.Equal(t, time.Unix(12, 0), groups[1].Last)
+}
+
+func TestSlowLog(t *testing.T) {
+	createGraph()
+	err := graph.ResetSlowLog()
+	assert.Nil(t, err)
+
+	_, err = graph.Query("UNWIND range(0, 10000) AS x RETURN count(x)")
+	assert.Nil(t, err)
+
+	entries, err := graph.SlowLog()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, entries)
+	assert.Equal(t, "GRAPH.QUERY", entries[0].Command)
+	assert.Equal(t, "UNWIND range(0, 10000) AS x RETURN count(x)", entries[0].Query)
+	assert.False(t, entries[0].Timestamp.IsZero())
+
+	err = graph.ResetSlowLog()
+	assert.Nil(t, err)
+	entries, err = graph.SlowLog()
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestConfig(t *testing.T) {
+	config, err := graph.Config()
+	assert.Nil(t, err)
+	assert.True(t, config.ThreadCount > 0, "Expecting THREAD_COUNT to be reported")
+	assert.Equal(t, config.ResultSetSize, config.Settings[CONFIG_RESULTSET_SIZE])
+
+	err = graph.ConfigSet(CONFIG_RESULTSET_SIZE, 100)
+	assert.Nil(t, err)
+	defer graph.ConfigSet(CONFIG_RESULTSET_SIZE, config.ResultSetSize)
+
+	v, err := graph.ConfigGet(CONFIG_RESULTSET_SIZE)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), v)
+
+	updated, err := graph.Config()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), updated.ResultSetSize)
+
+	// Invalid settings are rejected before reaching the server.
+	assert.NotNil(t, graph.ConfigSet("NO_SUCH_SETTING", 1))
+	assert.NotNil(t, graph.ConfigSet(CONFIG_THREAD_COUNT, 4))
+	assert.NotNil(t, graph.ConfigSet(CONFIG_RESULTSET_SIZE, -2))
+	assert.NotNil(t, graph.ConfigSet(CONFIG_TIMEOUT, "soon"))
+}
+
+func TestUnknownSchemaIndex(t *testing.T) {
+	node := []interface{}{int64(1), []interface{}{int64(5)}, []interface{}{}}
+	g := GraphNewWithExecutor("social", executorFunc(func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+		if cmd == "GRAPH.RO_QUERY" {
+			// The schema holds no label.
+			return []interface{}{
+				[]interface{}{[]interface{}{int64(COLUMN_SCALAR), "label"}},
+				[]interface{}{},
+				[]interface{}{},
+			}, nil
+		}
+		return []interface{}{
+			[]interface{}{[]interface{}{int64(COLUMN_SCALAR), "n"}},
+			[]interface{}{[]interface{}{[]interface{}{int64(VALUE_NODE), node}}},
+			[]interface{}{},
+		}, nil
+	}))
+	_, err := g.Query("MATCH (n) RETURN n")
+	parseErr, ok := err.(*ParseError)
+	assert.True(t, ok, "Expecting a ParseError")
+	assert.Equal(t, "unknown label index 5", parseErr.Msg)
+	assert.Equal(t, node, parseErr.Reply, "Expecting the raw reply rather than the index")
+}
+
+func TestConfigSettingReply(t *testing.T) {
+	v, err := parseConfigSetting([]interface{}{[]byte("RESULTSET_SIZE"), int64(100)}, CONFIG_RESULTSET_SIZE)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), v)
+
+	// Some versions wrap the pair within an array.
+	wrapped := []interface{}{[]interface{}{[]byte("RESULTSET_SIZE"), int64(100)}}
+	v, err = parseConfigSetting(wrapped, CONFIG_RESULTSET_SIZE)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), v)
+
+	g := GraphNewWithExecutor("social", executorFunc(func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+		return []interface{}{[]interface{}{"TIMEOUT", int64(500)}}, nil
+	}))
+	v, err = g.ConfigGet(CONFIG_TIMEOUT)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(500), v)
+
+	_, err = parseConfigSetting([]interface{}{[]byte("RESULTSET_SIZE")}, CONFIG_RESULTSET_SIZE)
+	_, ok := err.(*ParseError)
+	assert.True(t, ok, "Expecting a ParseError")
+}
+
+func TestClient(t *testing.T) {
+	createGraph()
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", "0.0.0.0:6379")
+	}}
+	defer pool.Close()
+
+	client := ClientNew(pool)
+	assert.True(t, client.Graph("social") == client.Graph("social"), "Expecting graph handles to be cached")
+
+	err := client.Copy("social", "social_copy")
+	assert.Nil(t, err)
+
+	tenant := client.Graph("tenant")
+	_, err = tenant.Query("CREATE (:Tenant {name: 'acme'})")
+	assert.Nil(t, err)
+
+	names, err := client.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"social", "social_copy", "tenant"}, names)
+
+	res, err := client.Graph("social_copy").ROQuery("MATCH (s)-[e]->(d) RETURN s,e,d")
+	assert.Nil(t, err)
+	checkQueryResults(t, res)
+
+	err = client.Delete("social_copy", "tenant")
+	assert.Nil(t, err)
+
+	names, err = client.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"social"}, names)
+
+	err = client.Delete("no_such_graph")
+	assert.IsType(t, &DeleteError{}, err)
+	assert.Contains(t, err.(*DeleteError).Errors, "no_such_graph")
+
+	assert.Equal(t, 0, pool.ActiveCount(), "Expecting all connections to be returned to the pool")
+}
+
+func TestCommitQuery(t *testing.T) {
+	g := GraphNew("commit", nil)
+
+	john := NodeNew([]string{"Person"}, "john", map[string]interface{}{"name": "John", "tags": []string{"a"}})
+	john.ID = 1
+	john.markCommitted()
+	g.AddNode(john)
+
+	cq, err := g.buildCommit()
+	assert.Nil(t, err)
+	assert.True(t, cq.empty(), "Expecting committed entities not to be re-created")
+
+	john.Properties["tags"].([]string)[0] = "b"
+	delete(john.Properties, "name")
+	john.Labels = append(john.Labels, "Admin")
+	japan := NodeNew([]string{"Country"}, "japan", nil)
+	g.AddNode(japan)
+	visit := EdgeNew("Visited", john, japan, nil)
+	visit.alias = "v"
+	assert.Nil(t, g.AddEdge(visit))
+
+	cq, err = g.buildCommit()
+	assert.Nil(t, err)
+	assert.Equal(t, "MATCH (john) WHERE id(john) = 1 CREATE (japan:Country),(john)-[v:Visited]->(japan) "+
+		"SET john:Admin,john.name = null,john.tags = [\"b\"] RETURN id(japan),id(v)", cq.String())
+
+	g.Edges = nil
+	g.removedEdges = []*Edge{{ID: 7, alias: "old", persisted: true, srcNodeID: 1, destNodeID: 2}}
+	g.RemoveNode(japan)
+	john.markCommitted()
+
+	cq, err = g.buildCommit()
+	assert.Nil(t, err)
+	assert.Equal(t, "MATCH ()-[old]->() WHERE id(old) = 7 DELETE old RETURN count(*)", cq.String())
+}
+
+func TestIncrementalCommit(t *testing.T) {
+	g := GraphNew("incremental", graph.Conn)
+	defer g.Delete()
+
+	john := NodeNew([]string{"Person"}, "john", map[string]interface{}{"name": "John Doe", "age": 33})
+	japan := NodeNew([]string{"Country"}, "japan", map[string]interface{}{"name": "Japan"})
+	visit := EdgeNew("Visited", john, japan, map[string]interface{}{"year": 2017})
+	g.AddNode(john)
+	g.AddNode(japan)
+	assert.Nil(t, g.AddEdge(visit))
+
+	res, err := g.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res.NodesCreated(), "Expecting 2 nodes created")
+	assert.Equal(t, 1, res.RelationshipsCreated(), "Expecting 1 relationship created")
+	assert.True(t, res.Empty(), "Expecting empty resultset")
+
+	// Committing again without changes is a no-op.
+	res, err = g.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.NodesCreated(), "Expecting no nodes created")
+
+	john.SetProperty("age", 34)
+	g.RemoveEdge(visit)
+	china := NodeNew([]string{"Country"}, "china", map[string]interface{}{"name": "China"})
+	g.AddNode(china)
+	assert.Nil(t, g.AddEdge(EdgeNew("Visited", john, china, nil)))
+
+	res, err = g.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res.NodesCreated(), "Expecting 1 node created")
+	assert.Equal(t, 1, res.RelationshipsCreated(), "Expecting 1 relationship created")
+	assert.Equal(t, 1, res.RelationshipsDeleted(), "Expecting 1 relationship deleted")
+
+	res, err = g.ROQuery("MATCH (p:Person)-[:Visited]->(c:Country) RETURN p.age, c.name")
+	assert.Nil(t, err)
+	assert.True(t, res.Next())
+	assert.Equal(t, []interface{}{34, "China"}, res.Record().Values())
+	assert.False(t, res.Next())
+
+	// Entities retrieved by a query are committed in place.
+	res, err = g.ROQuery("MATCH (c:Country {name: 'China'}) RETURN c")
+	assert.Nil(t, err)
+	res.Next()
+	fetched := res.Record().GetByIndex(0).(*Node)
+	assert.Equal(t, china.ID, fetched.ID)
+
+	other := GraphNew("incremental", graph.Conn)
+	other.AddNode(fetched)
+	fetched.SetProperty("population", 1400000000)
+	res, err = other.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.NodesCreated(), "Expecting no nodes created")
+	assert.Equal(t, 1, res.PropertiesSet(), "Expecting 1 property set")
+
+	g.RemoveNode(japan)
+	res, err = g.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res.NodesDeleted(), "Expecting 1 node deleted")
+
+	// Modifying an entity deleted behind the graph's back fails the commit.
+	_, err = g.Query("MATCH (p:Person) DELETE p")
+	assert.Nil(t, err)
+	john.SetProperty("age", 35)
+	_, err = g.Commit()
+	assert.Equal(t, ErrEntityNotFound, err)
+}
+
+func TestCommitBatches(t *testing.T) {
+	g := GraphNew("batches", nil)
+	for i := 0; i < 5; i++ {
+		g.AddNode(NodeNew([]string{"Person"}, fmt.Sprintf("p%d", i), map[string]interface{}{"id": i}))
+	}
+	g.AddNode(NodeNew([]string{"Country"}, "japan", map[string]interface{}{"name": "Japan"}))
+
+	batches := g.nodeBatches(2)
+	assert.Equal(t, 4, len(batches), "Expecting countries and persons to be batched separately")
+	assert.Equal(t, "UNWIND $rows AS row CREATE (n:Country {name: row.p.name}) RETURN row.i, id(n)", batches[0].query)
+	assert.Equal(t, "UNWIND $rows AS row CREATE (n:Person {id: row.p.id}) RETURN row.i, id(n)", batches[1].query)
+	assert.Equal(t, 2, len(batches[1].rows))
+	assert.Equal(t, 1, len(batches[3].rows))
+
+	for i, n := range []string{"p0", "p1", "japan"} {
+		g.Nodes[n].ID = uint64(i)
+		g.Nodes[n].markCommitted()
+	}
+	assert.Nil(t, g.AddEdge(EdgeNew("Visited", g.Nodes["p0"], g.Nodes["japan"], nil)))
+	assert.Nil(t, g.AddEdge(EdgeNew("Visited", g.Nodes["p1"], g.Nodes["japan"], map[string]interface{}{"year": 2017})))
+
+	assert.Equal(t, 2, len(g.nodeBatches(2)), "Expecting committed nodes to be skipped")
+	batches = g.edgeBatches(10)
+	assert.Equal(t, 1, len(batches))
+	assert.Equal(t, "UNWIND $rows AS row MATCH (s) WHERE id(s) = row.s MATCH (d) WHERE id(d) = row.d "+
+		"CREATE (s)-[e:Visited {year: row.p.year}]->(d) RETURN row.i, id(e)", batches[0].query)
+	assert.Equal(t, map[string]interface{}{"i": 1, "s": uint64(1), "d": uint64(2), "p": map[string]interface{}{"year": 2017}}, batches[0].rows[1])
+
+	_, err := g.CommitBatched(0)
+	assert.NotNil(t, err)
+}
+
+func TestCommitBatched(t *testing.T) {
+	g := GraphNew("batched", graph.Conn)
+	defer g.Delete()
+
+	hub := NodeNew([]string{"Hub"}, "hub", nil)
+	g.AddNode(hub)
+	for i := 0; i < 25; i++ {
+		n := NodeNew([]string{"Spoke"}, fmt.Sprintf("s%d", i), map[string]interface{}{"id": i})
+		g.AddNode(n)
+		assert.Nil(t, g.AddEdge(EdgeNew("Links", hub, n, map[string]interface{}{"weight": i})))
+	}
+
+	res, err := g.CommitBatched(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 26, res.NodesCreated(), "Expecting 26 nodes created")
+	assert.Equal(t, 25, res.RelationshipsCreated(), "Expecting 25 relationships created")
+	assert.Equal(t, 50, res.PropertiesSet(), "Expecting 50 properties set")
+
+	res, err = g.ROQuery("MATCH (:Hub)-[l:Links]->(s:Spoke) WHERE l.weight = s.id RETURN count(s)")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, 25, res.Record().GetByIndex(0))
+
+	// Everything has been committed.
+	res, err = g.CommitBatched(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.NodesCreated(), "Expecting no nodes created")
+
+	// Edges referencing nodes deleted in the meantime fail their batch.
+	_, err = g.Query("MATCH (h:Hub) DELETE h")
+	assert.Nil(t, err)
+	assert.Nil(t, g.AddEdge(EdgeNew("Links", hub, g.Nodes["s0"], nil)))
+	_, err = g.CommitBatched(10)
+	assert.IsType(t, &BatchError{}, err)
+	assert.Equal(t, 0, err.(*BatchError).Batch)
+	assert.Equal(t, ErrEntityNotFound, err.(*BatchError).Err)
+}
+
+func TestCommitBatchedPartial(t *testing.T) {
+	// Delete an endpoint once the node batch has been committed, before the
+	// edge batch is issued.
+	queries := 0
+	g := GraphNewWithExecutor("batched_partial", executorFunc(func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+		r, err := graph.Conn.Do(cmd, args...)
+		if queries++; queries == 1 && err == nil {
+			_, err = graph.Conn.Do("GRAPH.QUERY", "batched_partial", "MATCH (n {name: 'c'}) DELETE n")
+		}
+		return r, err
+	}))
+	defer g.Delete()
+
+	a := NodeNew([]string{"N"}, "a", map[string]interface{}{"name": "a"})
+	b := NodeNew([]string{"N"}, "b", map[string]interface{}{"name": "b"})
+	c := NodeNew([]string{"N"}, "c", map[string]interface{}{"name": "c"})
+	g.AddNode(a)
+	g.AddNode(b)
+	g.AddNode(c)
+	ab := EdgeNew("Links", a, b, nil)
+	ac := EdgeNew("Links", a, c, nil)
+	assert.Nil(t, g.AddEdge(ab))
+	assert.Nil(t, g.AddEdge(ac))
+
+	res, err := g.CommitBatched(10)
+	assert.IsType(t, &BatchError{}, err)
+	assert.Equal(t, 1, err.(*BatchError).Batch)
+	assert.Equal(t, ErrEntityNotFound, err.(*BatchError).Err)
+	assert.Equal(t, []*Edge{ac}, err.(*BatchError).Edges)
+	assert.Equal(t, 3, res.NodesCreated(), "Expecting the statistics of the applied batches")
+	assert.Equal(t, 1, res.RelationshipsCreated(), "Expecting the statistics of the partial batch")
+	assert.True(t, ab.persisted, "Expecting the created edge to be committed")
+	assert.False(t, ac.persisted)
+
+	// Dropping the orphaned edge, nothing is created twice.
+	g.Edges = []*Edge{ab}
+	res, err = g.CommitBatched(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.RelationshipsCreated())
+
+	res, err = g.ROQuery("MATCH ()-[l:Links]->() RETURN count(l)")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, 1, res.Record().GetByIndex(0))
+}
+
+func TestBulkEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeBulkCSVValue(&buf, "[1, 'a,b', [true]]", PROPERTY_INFERRED)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{
+		bulkArray, 3, 0, 0, 0, 0, 0, 0, 0,
+		bulkLong, 1, 0, 0, 0, 0, 0, 0, 0,
+		bulkString, 'a', ',', 'b', 0,
+		bulkArray, 1, 0, 0, 0, 0, 0, 0, 0, bulkBool, 1,
+	}, buf.Bytes())
+
+	assert.Equal(t, int64(42), inferCSVValue("42"))
+	assert.Equal(t, 4.5, inferCSVValue("4.5"))
+	assert.Equal(t, true, inferCSVValue("TRUE"))
+	assert.Equal(t, "NaN", inferCSVValue("NaN"))
+	assert.Equal(t, "[unterminated", inferCSVValue("[unterminated"))
+
+	buf.Reset()
+	assert.Nil(t, writeBulkCSVValue(&buf, "", PROPERTY_INTEGER))
+	assert.Equal(t, []byte{bulkNull}, buf.Bytes())
+	assert.NotNil(t, writeBulkCSVValue(&buf, "4.5", PROPERTY_INTEGER))
+	assert.NotNil(t, writeBulkValue(&buf, reflect.ValueOf(map[string]interface{}{})))
+
+	g := newBulkGroup("Person", []string{"name", "age"}, false)
+	assert.Equal(t, []byte("Person\x00\x02\x00\x00\x00name\x00age\x00"), g.header)
+
+	_, _, err = parseBulkReply([]byte("2 nodes created, 1 edges created"))
+	assert.Nil(t, err)
+	_, _, err = parseBulkReply("OK")
+	assert.IsType(t, &ParseError{}, err)
+}
+
+func TestBulkLoad(t *testing.T) {
+	g := GraphNew("bulk", graph.Conn)
+	defer g.Delete()
+
+	people := strings.NewReader("id,name,age\n1,John Doe,33\n2,Jane Doe,\n")
+	knows := strings.NewReader("src,dst,since\n1,2,2017\n")
+	japan := NodeNew([]string{"Country"}, "", map[string]interface{}{"name": "Japan", "tags": []string{"island"}})
+	john := NodeNew([]string{"Person"}, "", map[string]interface{}{"name": "Johnny"})
+	visit := EdgeNew("Visited", john, japan, nil)
+
+	var progress []BulkProgress
+	options := NewBulkOptions().SetBatchSize(64).SetProgress(func(p BulkProgress) {
+		progress = append(progress, p)
+	})
+	res, err := g.BulkLoad(options,
+		BulkEdgesCSV("Knows", knows, nil),
+		BulkNodesCSV("Person", people, map[string]PropertyType{"id": PROPERTY_STRING}),
+		BulkNodes([]*Node{japan, john}),
+		BulkEdges([]*Edge{visit}),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, res.NodesCreated, "Expecting 4 nodes created")
+	assert.Equal(t, 2, res.EdgesCreated, "Expecting 2 edges created")
+	assert.True(t, res.Batches > 1, "Expecting multiple batches")
+	assert.Equal(t, res.Batches, len(progress))
+	assert.Equal(t, 4, progress[len(progress)-1].TotalNodes)
+
+	q := "MATCH (a:Person {id: '1'})-[k:Knows]->(b:Person) RETURN a.age, b.name, b.age, k.since"
+	qr, err := g.ROQuery(q)
+	assert.Nil(t, err)
+	assert.True(t, qr.Next())
+	assert.Equal(t, []interface{}{33, "Jane Doe", nil, 2017}, qr.Record().Values())
+
+	qr, err = g.ParameterizedQuery("MATCH (p)-[:Visited]->(c) WHERE id(p) = $p AND id(c) = $c RETURN c.tags",
+		map[string]interface{}{"p": john.ID, "c": japan.ID})
+	assert.Nil(t, err)
+	assert.True(t, qr.Next())
+	assert.Equal(t, []interface{}{"island"}, qr.Record().GetByIndex(0))
+
+	// The graph must not exist.
+	_, err = g.BulkLoad(nil, BulkNodes([]*Node{NodeNew([]string{"Person"}, "", nil)}))
+	assert.IsType(t, &BatchError{}, err)
+}
+
+func TestPipeline(t *testing.T) {
+	createGraph()
+
+	p := graph.Pipeline().
+		ROQuery("MATCH (s)-[e]->(d) RETURN s,e,d", nil, nil).
+		Query("CREATE (:Person {name: $name})", map[string]interface{}{"name": "Jane Doe"}, nil).
+		Query("RETURN $invalid", map[string]interface{}{"not valid": 1}, nil).
+		ROQuery("CREATE (:Person)", nil, nil).
+		ROQuery("MATCH (p:Person) RETURN count(p)", nil, NewQueryOptions().SetTimeout(1000))
+	assert.Equal(t, 5, p.Len())
+
+	results, err := p.Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(results))
+	assert.Equal(t, 0, p.Len(), "Expecting the pipeline to be emptied")
+
+	assert.Nil(t, results[0].Err)
+	checkQueryResults(t, results[0].Result)
+
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, 1, results[1].Result.NodesCreated(), "Expecting 1 node created")
+
+	// Failing queries do not affect the others.
+	assert.NotNil(t, results[2].Err, "Expecting invalid parameters to be reported")
+	assert.NotNil(t, results[3].Err, "Expecting a write within a read only query to fail")
+
+	assert.Nil(t, results[4].Err)
+	results[4].Result.Next()
+	assert.Equal(t, 2, results[4].Result.Record().GetByIndex(0))
+}
+
+func TestTx(t *testing.T) {
+	createGraph()
+
+	tx, err := graph.Begin()
+	assert.Nil(t, err)
+	results, err := tx.
+		Query("CREATE (:Person {name: $name})", map[string]interface{}{"name": "Jane Doe"}, nil).
+		Command("SET", "people", 2).
+		ROQuery("MATCH (p:Person) RETURN count(p)", nil, nil).
+		Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, 1, results[0].Result.NodesCreated(), "Expecting 1 node created")
+	assert.Equal(t, "OK", results[1].Reply)
+	results[2].Result.Next()
+	assert.Equal(t, 2, results[2].Result.Record().GetByIndex(0))
+
+	_, err = tx.Exec()
+	assert.Equal(t, ErrTxDone, err)
+
+	// Modifying a watched key aborts the transaction.
+	tx, err = graph.Begin()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Watch(graph.Id))
+	other, err := redis.Dial("tcp", "0.0.0.0:6379")
+	assert.Nil(t, err)
+	defer other.Close()
+	_, err = other.Do("GRAPH.QUERY", graph.Id, "CREATE (:Person)")
+	assert.Nil(t, err)
+	_, err = tx.Query("CREATE (:Person)", nil, nil).Exec()
+	assert.Equal(t, ErrTxAborted, err)
+
+	// Invalid queries discard the whole transaction.
+	tx, err = graph.Begin()
+	assert.Nil(t, err)
+	_, err = tx.Command("SET", "people", 3).Query("RETURN $x", map[string]interface{}{"not valid": 1}, nil).Exec()
+	assert.NotNil(t, err)
+	people, err := redis.Int(graph.Conn.Do("GET", "people"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, people)
+
+	tx, err = graph.Begin()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Discard())
+	assert.Equal(t, ErrTxDone, tx.Discard())
+}
+
+func TestLazyResult(t *testing.T) {
+	cell := func(v int64) interface{} {
+		return []interface{}{[]interface{}{int64(VALUE_INTEGER), v}}
+	}
+	response := []interface{}{
+		[]interface{}{[]interface{}{int64(COLUMN_SCALAR), []byte("n")}},
+		[]interface{}{cell(1), cell(2), []interface{}{"malformed"}},
+		[]interface{}{},
+	}
+
+	res, err := queryResultNew(context.Background(), &Graph{}, response, NewQueryOptions().SetLazy(true))
+	assert.Nil(t, err)
+	assert.False(t, res.Empty())
+
+	assert.True(t, res.Next())
+	assert.Equal(t, 1, res.Record().GetByIndex(0))
+	assert.True(t, res.Next())
+	assert.Equal(t, 2, res.Record().GetByIndex(0))
+	assert.Equal(t, 1, len(res.pending), "Expecting decoded rows to be released")
+
+	assert.False(t, res.Next(), "Expecting a malformed row to stop the iteration")
+	assert.NotNil(t, res.Err())
+}
+
+func TestPaginate(t *testing.T) {
+	graph.Flush()
+	graph.Delete()
+	_, err := graph.Query("UNWIND range(1, 25) AS i CREATE (:Item {i: i})")
+	assert.Nil(t, err)
+
+	var items []int
+	p := graph.Paginate("MATCH (n:Item) RETURN n.i ORDER BY n.i", nil, 10)
+	for p.Next() {
+		items = append(items, p.Record().GetByIndex(0).(int))
+	}
+	assert.Nil(t, p.Err())
+	assert.Equal(t, 25, len(items))
+	assert.Equal(t, 25, items[24])
+
+	items = items[:0]
+	p = graph.Paginate("MATCH (n:Item) WHERE n.i > $cursor RETURN n.i AS cursor ORDER BY cursor", nil, 10).
+		WithCursor("cursor", 5)
+	for p.Next() {
+		items = append(items, p.Record().GetByIndex(0).(int))
+	}
+	assert.Nil(t, p.Err())
+	assert.Equal(t, 20, len(items))
+	assert.Equal(t, 6, items[0])
+
+	p = graph.Paginate("MATCH (n:Item) WHERE n.i > $cursor RETURN n.i ORDER BY n.i", nil, 10).
+		WithCursor("cursor", 0)
+	assert.False(t, p.Next())
+	assert.NotNil(t, p.Err(), "Expecting a missing cursor column to be reported")
+}
+
+func TestValueTypes(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	params, err := EncodeParamsHeader(map[string]interface{}{
+		"at":  at,
+		"d":   90 * time.Minute,
+		"loc": Point{Latitude: 32.5, Longitude: -117},
+		"vec": Vector32{1, 2.5},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `CYPHER at=localdatetime("2021-03-04T05:06:07") d=duration({seconds: 5400}) `+
+		`loc=point({latitude: 32.5, longitude: -117.0}) vec=vecf32([1.0,2.5]) `, params)
+
+	// Times are sent in UTC, fractional seconds can not be stored.
+	s, err := EncodeValue(at.In(time.FixedZone("UTC+2", 2*60*60)))
+	assert.Nil(t, err)
+	assert.Equal(t, `localdatetime("2021-03-04T05:06:07")`, s)
+	for _, v := range []interface{}{at.Add(time.Millisecond), 1500 * time.Millisecond} {
+		_, err = EncodeValue(v)
+		_, ok := err.(*UnsupportedValueError)
+		assert.True(t, ok, "Expecting %v to be rejected", v)
+	}
+
+	qr := newQueryResult(&Graph{})
+	for _, c := range []struct {
+		t        ResultSetScalarTypes
+		v        interface{}
+		expected interface{}
+	}{
+		{VALUE_POINT, []interface{}{[]byte("32.5"), []byte("-117")}, Point{Latitude: 32.5, Longitude: -117}},
+		{VALUE_VECTORF32, []interface{}{[]byte("1"), []byte("2.5")}, Vector32{1, 2.5}},
+		{VALUE_DATETIME, at.Unix(), at.Truncate(time.Second)},
+		{VALUE_DATE, int64(86400), time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{VALUE_DURATION, int64(5400), 90 * time.Minute},
+	} {
+		v, err := qr.parseScalar([]interface{}{int64(c.t), c.v})
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, v)
+	}
+}
+
+func TestTemporalQuery(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	q := "RETURN $at, $d, $loc, $vec"
+	res, err := graph.ParameterizedQuery(q, map[string]interface{}{
+		"at":  at,
+		"d":   90 * time.Minute,
+		"loc": Point{Latitude: 32.5, Longitude: -117},
+		"vec": Vector32{1, 2.5},
+	})
+	assert.Nil(t, err)
+	res.Next()
+	r := res.Record()
+	assert.Equal(t, at, r.GetByIndex(0))
+	assert.Equal(t, 90*time.Minute, r.GetByIndex(1))
+	assert.Equal(t, Point{Latitude: 32.5, Longitude: -117}, r.GetByIndex(2))
+	assert.Equal(t, Vector32{1, 2.5}, r.GetByIndex(3))
+
+	// Values round-trip to the same instant, in UTC.
+	zoned := at.In(time.FixedZone("UTC-7", -7*60*60))
+	res, err = graph.ParameterizedQuery("RETURN $at", map[string]interface{}{"at": zoned})
+	assert.Nil(t, err)
+	res.Next()
+	assert.True(t, zoned.Equal(res.Record().GetByIndex(0).(time.Time)))
+
+	_, err = graph.ParameterizedQuery("RETURN $at", map[string]interface{}{"at": at.Add(500 * time.Millisecond)})
+	assert.NotNil(t, err, "Expecting sub-second times to be rejected rather than truncated")
+	_, err = graph.ParameterizedQuery("RETURN $d", map[string]interface{}{"d": 1500 * time.Millisecond})
+	assert.NotNil(t, err, "Expecting sub-second durations to be rejected rather than truncated")
+}
+
+func TestIntegerBoundaries(t *testing.T) {
+	for _, c := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{int8(math.MinInt8), "-128"},
+		{int16(math.MaxInt16), "32767"},
+		{int32(math.MinInt32), "-2147483648"},
+		{int64(math.MaxInt64), "9223372036854775807"},
+		{int64(math.MinInt64), "-9223372036854775808"},
+		{uint8(math.MaxUint8), "255"},
+		{uint32(math.MaxUint32), "4294967295"},
+		{uint64(math.MaxInt64), "9223372036854775807"},
+	} {
+		s, err := EncodeValue(c.v)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, s)
+	}
+	_, err := EncodeValue(uint64(math.MaxInt64 + 1))
+	assert.NotNil(t, err, "Expecting uint64 values overflowing int64 to be rejected")
+
+	g := &Graph{}
+	qr := newQueryResult(g)
+	v, err := qr.parseScalar([]interface{}{int64(VALUE_INTEGER), int64(math.MaxInt32)})
+	assert.Nil(t, err)
+	assert.Equal(t, math.MaxInt32, v)
+
+	g.SetIntegerDecoding(INTEGER_INT64)
+	for _, i := range []int64{math.MinInt64, -1, 0, math.MaxInt64} {
+		v, err = qr.parseScalar([]interface{}{int64(VALUE_INTEGER), i})
+		assert.Nil(t, err)
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestIntegerRoundTrip(t *testing.T) {
+	g := GraphNew(graph.Id, graph.Conn)
+	g.SetIntegerDecoding(INTEGER_INT64)
+
+	params := map[string]interface{}{
+		// The minimum int64 has no literal, its absolute value overflows.
+		"min": int64(math.MinInt64 + 1),
+		"max": int64(math.MaxInt64),
+		"u32": uint32(math.MaxUint32),
+		"u64": uint64(math.MaxInt64),
+	}
+	res, err := g.ParameterizedQuery("RETURN $min, $max, $u32, $u64", params)
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, []interface{}{
+		int64(math.MinInt64 + 1), int64(math.MaxInt64), int64(math.MaxUint32), int64(math.MaxInt64),
+	}, res.Record().Values())
+}
+
+func TestRecordAccessors(t *testing.T) {
+	keys := []string{"name", "age", "score", "active", "n", "tags", "props", "empty", "name"}
+	n := NodeNew([]string{"Person"}, "", nil)
+	r := recordNew([]interface{}{
+		"John Doe", 33, 1.5, true, n, []interface{}{"a"}, map[string]interface{}{"k": 1}, nil, "shadowed",
+	}, keys, columnIndex(keys))
+
+	name, err := r.GetString("name")
+	assert.Nil(t, err)
+	assert.Equal(t, "John Doe", name, "Expecting the first of duplicate columns")
+	age, err := r.GetInt64("age")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(33), age)
+	score, err := r.GetFloat64ByIndex(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, score)
+	active, err := r.GetBool("active")
+	assert.Nil(t, err)
+	assert.True(t, active)
+	node, err := r.GetNode("n")
+	assert.Nil(t, err)
+	assert.Equal(t, n, node)
+	tags, err := r.GetList("tags")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"a"}, tags)
+	props, err := r.GetMapByIndex(6)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, props["k"])
+	_, err = r.GetEdge("empty")
+	assert.Equal(t, ErrNull, err)
+	_, err = r.GetNode("empty")
+	assert.Equal(t, ErrNull, err)
+	_, err = r.GetString("empty")
+	assert.Equal(t, ErrNull, err)
+
+	// Values are not converted.
+	_, err = r.GetInt64("name")
+	assert.Equal(t, &TypeError{Column: "name", Index: 0, Type: reflect.TypeOf(int64(0)), Value: "John Doe"}, err)
+	_, err = r.GetString("age")
+	assert.IsType(t, &TypeError{}, err)
+	_, err = r.GetStringByIndex(3)
+	assert.IsType(t, &TypeError{}, err)
+	_, err = r.GetInt64("score")
+	assert.IsType(t, &TypeError{}, err)
+	_, err = r.GetFloat64("age")
+	assert.IsType(t, &TypeError{}, err)
+	_, err = r.GetNode("props")
+	assert.IsType(t, &TypeError{}, err)
+
+	wide := recordNew([]interface{}{int64(math.MaxInt64), 2.0}, []string{"i", "f"}, columnIndex([]string{"i", "f"}))
+	i, err := wide.GetInt64("i")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(math.MaxInt64), i)
+	_, err = wide.GetInt64("f")
+	assert.IsType(t, &TypeError{}, err)
+	_, err = r.GetBool("missing")
+	assert.Equal(t, &ColumnError{Key: "missing", Index: -1}, err)
+	_, err = r.GetPathByIndex(9)
+	assert.Equal(t, &ColumnError{Index: 9}, err)
+}
+
+func TestRender(t *testing.T) {
+	keys := []string{"p", "name", "tags"}
+	john := NodeNew([]string{"Person"}, "", map[string]interface{}{"age": 33})
+	john.ID = 1
+	qr := newQueryResult(&Graph{})
+	qr.header.column_names = keys
+	qr.results = []*Record{
+		recordNew([]interface{}{john, "John, Doe", []interface{}{"a", 1}}, keys, columnIndex(keys)),
+		recordNew([]interface{}{nil, "x|y", nil}, keys, columnIndex(keys)),
+	}
+	qr.statistics = map[string]float64{NODES_CREATED: 2, INTERNAL_EXECUTION_TIME: 0.25}
+
+	render := func(format RenderFormat) string {
+		var buf bytes.Buffer
+		assert.Nil(t, qr.Render(&buf, format))
+		return buf.String()
+	}
+
+	assert.Equal(t, "p,name,tags\n"+
+		`(1:Person {age: 33}),"John, Doe","[""a"", 1]"`+"\n"+
+		",x|y,\n", render(FORMAT_CSV))
+	assert.Equal(t, "p\tname\ttags\n"+
+		`(1:Person {age: 33})`+"\tJohn, Doe\t"+`"[""a"", 1]"`+"\n"+
+		"\tx|y\t\n", render(FORMAT_TSV))
+	assert.Equal(t, "| p | name | tags |\n"+
+		"| --- | --- | --- |\n"+
+		`| (1:Person {age: 33}) | John, Doe | ["a", 1] |`+"\n"+
+		`| null | x\|y | null |`+"\n"+
+		"\nNodes created: 2\nQuery internal execution time: 0.25\n", render(FORMAT_MARKDOWN))
+	assert.Equal(t, `{"name":"John, Doe","p":{"$type":"node","id":1,"labels":["Person"],"properties":{"age":33}},"tags":["a",1]}`+"\n"+
+		`{"name":"x|y","p":null,"tags":null}`+"\n", render(FORMAT_JSONL))
+	assert.Contains(t, render(FORMAT_TABLE), "| (1:Person {age: 33}) | John, Doe | [\"a\", 1] |")
+
+	qr.header.column_names = nil
+	qr.results = nil
+	assert.Equal(t, "Nodes created: 2\nQuery internal execution time: 0.25\n", render(FORMAT_TABLE))
+}
+
+func TestJSON(t *testing.T) {
+	john := NodeNew([]string{"Person"}, "", map[string]interface{}{"age": 33, "height": 1.0})
+	john.ID = 1
+	japan := NodeNew([]string{"Country"}, "", nil)
+	japan.ID = 2
+	visited := EdgeNew("VISITED", john, japan, map[string]interface{}{"year": 2020})
+	visited.ID = 3
+	visited.markCommitted()
+	path := PathNew([]interface{}{john, japan}, []interface{}{visited})
+
+	data, err := json.Marshal(john)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"$type":"node","id":1,"labels":["Person"],"properties":{"age":33,"height":1.0}}`, string(data))
+	data, err = json.Marshal(visited)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"$type":"edge","id":3,"type":"VISITED","src":1,"dst":2,"properties":{"year":2020}}`, string(data))
+
+	// Values are decoded by their tag, maps shaped like other values remain maps.
+	misc := []interface{}{
+		nil, "a", 2.5, map[string]interface{}{"id": 1},
+		map[string]interface{}{"latitude": 1.5, "longitude": 2.5},
+		map[string]interface{}{"$type": "point", "latitude": 1.5},
+		map[string]interface{}{"id": 1, "labels": []interface{}{"Person"}, "properties": map[string]interface{}{}},
+		time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		90 * time.Minute,
+		Vector32{1, 2.5},
+	}
+	keys := []string{"p", "path", "loc", "misc"}
+	qr := newQueryResult(&Graph{})
+	qr.header.column_names = keys
+	qr.header.column_types = []ResultSetColumnTypes{COLUMN_SCALAR, COLUMN_SCALAR, COLUMN_SCALAR, COLUMN_SCALAR}
+	qr.results = []*Record{recordNew([]interface{}{
+		john, path, Point{Latitude: 1.5, Longitude: 2}, misc,
+	}, keys, columnIndex(keys))}
+	qr.statistics = map[string]float64{NODES_CREATED: 1}
+
+	data, err = json.Marshal(qr)
+	assert.Nil(t, err)
+	var decoded QueryResult
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 1, decoded.NodesCreated())
+	assert.True(t, decoded.Next())
+	r := decoded.Record()
+
+	n, err := r.GetNode("p")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), n.ID)
+	assert.Equal(t, john.Properties, n.Properties)
+	p, err := r.GetPath("path")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, p.NodesCount())
+	assert.Equal(t, uint64(2), p.GetEdge(0).DestNodeID())
+	assert.Equal(t, "VISITED", p.GetEdge(0).Relation)
+	v, _ := r.Get("loc")
+	assert.Equal(t, Point{Latitude: 1.5, Longitude: 2}, v)
+	list, err := r.GetList("misc")
+	assert.Nil(t, err)
+	assert.Equal(t, misc, list)
+	assert.False(t, decoded.Next())
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"keys":["v"],"values":[{"$type":"unknown"}]}`), &Record{}))
+
+	// Integers of graphs decoding them as int64 decode as int64 again.
+	g := GraphNew("social", nil)
+	g.SetIntegerDecoding(INTEGER_INT64)
+	qr = newQueryResult(&g)
+	qr.header.column_names = []string{"n", "list", "p"}
+	qr.header.column_types = []ResultSetColumnTypes{COLUMN_SCALAR, COLUMN_SCALAR, COLUMN_SCALAR}
+	qr.header.column_index = columnIndex(qr.header.column_names)
+	ann := NodeNew([]string{"Person"}, "", map[string]interface{}{"age": int64(33)})
+	ann.ID = 4
+	qr.results = []*Record{recordNew([]interface{}{
+		int64(1), []interface{}{int64(2), map[string]interface{}{"k": int64(3)}}, ann,
+	}, qr.header.column_names, qr.header.column_index)}
+	data, err = json.Marshal(qr)
+	assert.Nil(t, err)
+	decoded = QueryResult{}
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.Next())
+	r = decoded.Record()
+	v, _ = r.Get("n")
+	assert.Equal(t, int64(1), v)
+	v, _ = r.Get("list")
+	assert.Equal(t, []interface{}{int64(2), map[string]interface{}{"k": int64(3)}}, v)
+	n, err = r.GetNode("p")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(33), n.Properties["age"])
+
+	// As do results encoded again.
+	again, err := json.Marshal(&decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+// fakeClusterNode stands in for a Redis Cluster node, serving graph queries
+// for the slots it owns and redirecting the others.
+type fakeClusterNode struct {
+	cluster   *fakeCluster
+	addr      string
+	migrating map[int]string // Slots being migrated, to the given node.
+	served    int
+}
+
+type fakeCluster struct {
+	mutex sync.Mutex
+	nodes map[string]*fakeClusterNode
+	owner []string // Owning node address by slot.
+}
+
+func newFakeCluster(addrs ...string) *fakeCluster {
+	c := &fakeCluster{nodes: make(map[string]*fakeClusterNode), owner: make([]string, clusterSlots)}
+	for i, addr := range addrs {
+		c.nodes[addr] = &fakeClusterNode{cluster: c, addr: addr, migrating: make(map[int]string)}
+		for s := i * clusterSlots / len(addrs); s < (i+1)*clusterSlots/len(addrs); s++ {
+			c.owner[s] = addr
+		}
+	}
+	return c
+}
+
+func (c *fakeCluster) dial(addr string) (redis.Conn, error) {
+	n, ok := c.nodes[addr]
+	if !ok {
+		return nil, fmt.Errorf("unknown node %s", addr)
+	}
+	asking := false
+	return &fakeConn{handle: func(cmd string, args []interface{}) interface{} {
+		wasAsking := asking
+		asking = cmd == "ASKING"
+		return n.reply(cmd, args, wasAsking)
+	}}, nil
+}
+
+func (c *fakeCluster) slots() []interface{} {
+	var r []interface{}
+	for start := 0; start < clusterSlots; {
+		end := start
+		for end+1 < clusterSlots && c.owner[end+1] == c.owner[start] {
+			end++
+		}
+		host, port, _ := net.SplitHostPort(c.owner[start])
+		p, _ := strconv.Atoi(port)
+		r = append(r, []interface{}{int64(start), int64(end), []interface{}{[]byte(host), int64(p)}})
+		start = end + 1
+	}
+	return r
+}
+
+func (n *fakeClusterNode) reply(cmd string, args []interface{}, asking bool) interface{} {
+	cl := n.cluster
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	switch cmd {
+	case "ASKING":
+		return "OK"
+	case "CLUSTER":
+		return cl.slots()
+	case "GRAPH.LIST":
+		return []interface{}{[]byte("graph@" + n.addr)}
+	case "GRAPH.CONFIG":
+		n.served++
+		return "OK"
+	}
+
+	slot := Slot(args[0].(string))
+	owner := cl.owner[slot]
+	if owner != n.addr && !(asking && cl.nodes[owner].migrating[slot] == n.addr) {
+		return redis.Error(fmt.Sprintf("MOVED %d %s", slot, owner))
+	}
+	if target, ok := n.migrating[slot]; ok && !asking {
+		return redis.Error(fmt.Sprintf("ASK %d %s", slot, target))
+	}
+	n.served++
+	if cmd == "GRAPH.DELETE" {
+		return "OK"
+	}
+	return fakeReply(n.addr)
+}
+
+// fakeReply is a query reply holding a single string.
+func fakeReply(s string) interface{} {
+	return []interface{}{
+		[]interface{}{[]interface{}{int64(COLUMN_SCALAR), []byte("s")}},
+		[]interface{}{[]interface{}{[]interface{}{int64(VALUE_STRING), []byte(s)}}},
+		[]interface{}{},
+	}
+}
+
+// fakeConn is a connection whose replies are produced by handle, errors
+// replies are returned as redis.Error values and connection failures as error.
+type fakeConn struct {
+	handle  func(cmd string, args []interface{}) interface{}
+	replies []interface{}
+	err     error
+}
+
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Err() error   { return c.err }
+func (c *fakeConn) Flush() error { return c.err }
+
+func (c *fakeConn) Send(cmd string, args ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.replies = append(c.replies, c.handle(cmd, args))
+	return nil
+}
+
+func (c *fakeConn) Receive() (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	r := c.replies[0]
+	c.replies = c.replies[1:]
+	switch e := r.(type) {
+	case redis.Error:
+		return nil, e
+	case error:
+		c.err = e
+		return nil, e
+	}
+	return r, nil
+}
+
+func (c *fakeConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Receive()
+}
+
+func (c *fakeConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Do(cmd, args...)
+}
+
+func (c *fakeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd != "" {
+		if err := c.Send(cmd, args...); err != nil {
+			return nil, err
+		}
+	}
+	var reply interface{}
+	var err error
+	for len(c.replies) > 0 {
+		r, e := c.Receive()
+		if e != nil && err == nil {
+			err = e
+		}
+		reply = r
+	}
+	return reply, err
+}
+
+func (c *fakeConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.Do(cmd, args...)
+}
+
+func (c *fakeConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.Receive()
+}
+
+func TestCluster(t *testing.T) {
+	assert.Equal(t, 12182, Slot("foo"))
+	assert.Equal(t, Slot("user1000"), Slot("{user1000}.following"))
+	assert.Equal(t, Slot("{}x"), int(crc16("{}x"))%clusterSlots, "Expecting empty hash tags to be ignored")
+
+	fake := newFakeCluster("10.0.0.1:7000", "10.0.0.2:7000")
+	cluster := ClusterNew([]string{"10.0.0.1:7000"}, fake.dial)
+	defer cluster.Close()
+
+	g := GraphNewWithPool("social", cluster)
+	slot := Slot("social")
+	owner := fake.owner[slot]
+	other := "10.0.0.1:7000"
+	if owner == other {
+		other = "10.0.0.2:7000"
+	}
+
+	res, err := g.Query("MATCH (n) RETURN n")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, owner, res.Record().GetByIndex(0), "Expecting the query to be routed to the slot's owner")
+
+	// The slot is being migrated, the new node serves it once asked to.
+	fake.nodes[owner].migrating[slot] = other
+	res, err = g.ROQuery("MATCH (n) RETURN n")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, other, res.Record().GetByIndex(0), "Expecting ASK to be followed")
+
+	// The migration completed, the former owner redirects permanently.
+	fake.mutex.Lock()
+	delete(fake.nodes[owner].migrating, slot)
+	fake.owner[slot] = other
+	fake.mutex.Unlock()
+	res, err = g.Query("MATCH (n) RETURN n")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, other, res.Record().GetByIndex(0), "Expecting MOVED to be followed")
+	assert.Equal(t, other, cluster.nodeFor("social"), "Expecting the slot map to be updated")
+
+	served := fake.nodes[other].served
+	p := g.Pipeline().Query("RETURN 1", nil, nil).ROQuery("RETURN 2", nil, nil)
+	results, err := p.Exec()
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, served+2, fake.nodes[other].served)
+
+	client := ClientNew(cluster)
+	names, err := client.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"graph@10.0.0.1:7000", "graph@10.0.0.2:7000"}, names, "Expecting graphs of every master")
+	assert.NotEqual(t, Slot("a"), Slot("b"))
+	assert.NotNil(t, client.Copy("a", "b"), "Expecting cross slot copies to be rejected")
+	assert.Nil(t, client.Copy("{t}a", "{t}b"))
+
+	served1, served2 := fake.nodes["10.0.0.1:7000"].served, fake.nodes["10.0.0.2:7000"].served
+	assert.Nil(t, g.ConfigSet(CONFIG_TIMEOUT, 1000))
+	assert.Equal(t, served1+1, fake.nodes["10.0.0.1:7000"].served, "Expecting settings to be modified on every master")
+	assert.Equal(t, served2+1, fake.nodes["10.0.0.2:7000"].served, "Expecting settings to be modified on every master")
+
+	assert.Nil(t, g.Delete())
+}
+
+func TestSentinel(t *testing.T) {
+	var mutex sync.Mutex
+	master := "10.0.0.1:6379"
+	dial := func(addr string) (redis.Conn, error) {
+		if addr == "10.0.0.9:26379" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return &fakeConn{handle: func(cmd string, args []interface{}) interface{} {
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch {
+			case cmd == "SENTINEL" && addr == "10.0.0.11:26379":
+				// A sentinel yet to notice the failover.
+				return []interface{}{[]byte("10.0.0.1"), []byte("6379")}
+			case cmd == "SENTINEL" && args[1] == "social":
+				host, port, _ := net.SplitHostPort(master)
+				return []interface{}{[]byte(host), []byte(port)}
+			case cmd == "SENTINEL":
+				return nil
+			case cmd == "ROLE" && addr == master:
+				return []interface{}{[]byte("master"), int64(0), []interface{}{}}
+			case cmd == "ROLE":
+				return []interface{}{[]byte("slave"), []byte("10.0.0.2"), int64(6379), []byte("connected"), int64(0)}
+			case addr != master && cmd == "GRAPH.QUERY":
+				return redis.Error("READONLY You can't write against a read only replica.")
+			}
+			return fakeReply(addr)
+		}}, nil
+	}
+
+	s := SentinelNew([]string{"10.0.0.9:26379", "10.0.0.10:26379"}, "social", dial)
+	defer s.Close()
+	g := GraphNewWithPool("social", s)
+
+	res, err := g.Query("RETURN 1")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, "10.0.0.1:6379", res.Record().GetByIndex(0))
+
+	// Fail over, the former master is demoted to a replica.
+	mutex.Lock()
+	master = "10.0.0.2:6379"
+	mutex.Unlock()
+	_, err = g.Query("RETURN 1")
+	roErr, ok := err.(*ReadOnlyError)
+	assert.True(t, ok, "Expecting a ReadOnlyError")
+	assert.Equal(t, "10.0.0.1:6379", roErr.Addr)
+
+	res, err = g.Query("RETURN 1")
+	assert.Nil(t, err)
+	res.Next()
+	assert.Equal(t, "10.0.0.2:6379", res.Record().GetByIndex(0), "Expecting the new master to be used")
+	addr, err := s.MasterAddr()
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2:6379", addr)
+
+	conn := s.Get()
+	_, err = redis.DoWithTimeout(conn, time.Second, "PING")
+	assert.Nil(t, err)
+	conn.Close()
+
+	addr, err = SentinelNew([]string{"10.0.0.11:26379", "10.0.0.10:26379"}, "social", dial).MasterAddr()
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2:6379", addr, "Expecting nodes which are not masters to be skipped")
+
+	_, err = SentinelNew([]string{"10.0.0.10:26379"}, "unknown", dial).MasterAddr()
+	assert.NotNil(t, err)
+}
+
+func TestReplicas(t *testing.T) {
+	var mutex sync.Mutex
+	failures := map[string]interface{}{}
+	provider := func(name string) ConnProvider {
+		return &redis.Pool{Dial: func() (redis.Conn, error) {
+			return &fakeConn{handle: func(cmd string, args []interface{}) interface{} {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if f, ok := failures[name]; ok && cmd == "GRAPH.RO_QUERY" {
+					return f
+				}
+				if len(args) > 1 && args[1] == "fail" {
+					return redis.Error("Query failed")
+				}
+				if len(args) > 1 && args[1] == "fail at run-time" {
+					return []interface{}{redis.Error("Division by zero")}
+				}
+				return fakeReply(name)
+			}}, nil
+		}}
+	}
+	served := func(g *Graph, ro bool) string {
+		var res *QueryResult
+		var err error
+		if ro {
+			res, err = g.ROQuery("RETURN 1")
+		} else {
+			res, err = g.Query("RETURN 1")
+		}
+		assert.Nil(t, err)
+		res.Next()
+		return res.Record().GetByIndex(0).(string)
+	}
+
+	g := GraphNewWithPool("social", provider("primary"))
+	g.SetReplicas(provider("replica1"), provider("replica2"))
+
+	counts := map[string]int{}
+	for i := 0; i < 4; i++ {
+		counts[served(&g, true)]++
+	}
+	assert.Equal(t, map[string]int{"replica1": 2, "replica2": 2}, counts, "Expecting read only queries to be balanced across replicas")
+	assert.Equal(t, "primary", served(&g, false), "Expecting writes to go to the primary")
+
+	// An unreachable replica is skipped.
+	mutex.Lock()
+	failures["replica2"] = fmt.Errorf("connection reset by peer")
+	mutex.Unlock()
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "replica1", served(&g, true))
+	}
+
+	// Query errors are reported rather than retried elsewhere.
+	mutex.Lock()
+	failures["replica1"] = redis.Error("Invalid query")
+	mutex.Unlock()
+	_, err := g.ROQuery("RETURN 1")
+	assert.Equal(t, redis.Error("Invalid query"), err)
+
+	// With no healthy replica left, queries fall back to the primary.
+	mutex.Lock()
+	failures["replica1"] = redis.Error("LOADING Redis is loading the dataset in memory")
+	mutex.Unlock()
+	assert.Equal(t, "primary", served(&g, true))
+
+	// Read your writes pins read only queries to the primary after a write.
+	g = GraphNewWithPool("social", provider("primary"))
+	g.SetReplicas(provider("replica3"))
+	g.SetReadYourWrites(time.Minute)
+	assert.Equal(t, "replica3", served(&g, true))
+	assert.Equal(t, "primary", served(&g, false))
+	assert.Equal(t, "primary", served(&g, true), "Expecting the query to observe the latest write")
+	atomic.StoreInt64(&g.replicas.lastWrite, time.Now().Add(-time.Minute).UnixNano())
+	assert.Equal(t, "replica3", served(&g, true), "Expecting replicas to be used once the window elapsed")
+
+	// Reads and failed writes do not pin.
+	ctx := context.Background()
+	_, err = g.Query("fail")
+	assert.NotNil(t, err)
+	g.do(ctx, "GRAPH.EXPLAIN", "social", "RETURN 1")
+	g.do(ctx, "GRAPH.CONFIG", "GET", "*")
+	_, err = g.Pipeline().ROQuery("RETURN 1", nil, nil).Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, "replica3", served(&g, true))
+	results, err := g.Pipeline().Query("fail at run-time", nil, nil).Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, redis.Error("Division by zero"), results[0].Err)
+	assert.Equal(t, "replica3", served(&g, true), "Expecting writes failing within their reply not to pin")
+	_, err = g.procedureStrings(ctx, nil, "db.labels")
+	assert.Nil(t, err)
+	assert.Equal(t, "replica3", served(&g, true), "Expecting cache refreshes not to count as writes")
+
+	_, err = g.do(ctx, "GRAPH.CONFIG", "SET", "TIMEOUT", 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, "primary", served(&g, true))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var mutex sync.Mutex
+	var failures []interface{}
+	calls := 0
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return &fakeConn{handle: func(cmd string, args []interface{}) interface{} {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls++
+			if len(failures) > 0 {
+				f := failures[0]
+				failures = failures[1:]
+				return f
+			}
+			return fakeReply("ok")
+		}}, nil
+	}}
+	fail := func(errs ...interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		failures, calls = errs, 0
+	}
+
+	var retries []int
+	policy := NewRetryPolicy().SetBackoff(time.Millisecond, 4*time.Millisecond).
+		SetOnRetry(func(cmd string, attempt int, delay time.Duration, err error) {
+			assert.Equal(t, "GRAPH.RO_QUERY", cmd)
+			assert.True(t, delay <= 4*time.Millisecond)
+			assert.True(t, IsRetryable(err))
+			retries = append(retries, attempt)
+		})
+	g := GraphNewWithPool("social", pool)
+	g.SetRetryPolicy(policy)
+
+	fail(redis.Error("LOADING Redis is loading the dataset in memory"), &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("connection reset by peer")})
+	_, err := g.ROQuery("RETURN 1")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{1, 2}, retries)
+
+	// Attempts are bounded.
+	fail(redis.Error("Max pending queries exceeded"), redis.Error("Max pending queries exceeded"), redis.Error("Max pending queries exceeded"))
+	_, err = g.ROQuery("RETURN 1")
+	assert.Equal(t, redis.Error("Max pending queries exceeded"), err)
+	assert.Equal(t, 3, calls)
+
+	// Query errors are not retried.
+	fail(redis.Error("Invalid query"))
+	_, err = g.ROQuery("RETURN 1")
+	assert.Equal(t, redis.Error("Invalid query"), err)
+	assert.Equal(t, 1, calls)
+
+	// Writes are retried only once enabled.
+	fail(redis.Error("READONLY You can't write against a read only replica."))
+	_, err = g.Query("CREATE ()")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+	policy.SetRetryWrites(true).SetOnRetry(nil)
+	fail(&ReadOnlyError{Addr: "10.0.0.1:6379"})
+	_, err = g.Query("CREATE ()")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Cancellation interrupts the backoff.
+	policy.SetBackoff(time.Minute, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	fail(redis.Error("LOADING Redis is loading the dataset in memory"))
+	_, err = g.ROQueryContext(ctx, "RETURN 1", nil, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// Without a policy nothing is retried.
+	g.SetRetryPolicy(nil)
+	fail(redis.Error("LOADING Redis is loading the dataset in memory"))
+	_, err = g.ROQuery("RETURN 1")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Wrapped errors are classified by the error they wrap.
+	assert.True(t, IsRetryable(&BatchError{Err: io.EOF}))
+	assert.True(t, IsRetryable(&BatchError{Err: &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("connection reset by peer")}}))
+	assert.False(t, IsRetryable(&BatchError{Err: redis.Error("Invalid query")}))
+
+	// A broken connection of a graph created by GraphNew is not retried.
+	retried := false
+	conn := GraphNew("social", &fakeConn{err: &net.OpError{Op: "write", Net: "tcp", Err: fmt.Errorf("broken pipe")}})
+	conn.SetRetryPolicy(NewRetryPolicy().SetOnRetry(func(string, int, time.Duration, error) { retried = true }))
+	_, err = conn.ROQuery("RETURN 1")
+	assert.NotNil(t, err)
+	assert.False(t, retried)
+
+	p := NewRetryPolicy().SetBackoff(100*time.Millisecond, time.Second)
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		d := p.backoff(attempt + 1)
+		max *= time.Millisecond
+		assert.True(t, d > max/2-time.Nanosecond && d <= max, "Unexpected backoff %v following attempt %d", d, attempt+1)
+	}
+}
+
+// executorFunc adapts a function to the Executor interface.
+type executorFunc func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+
+func (f executorFunc) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return f(ctx, cmd, args...)
+}
+
+func TestExecutor(t *testing.T) {
+	// A reply as returned by go-redis over RESP3: strings rather than []byte
+	// and doubles as float64.
+	reply := func() interface{} {
+		return []interface{}{
+			[]interface{}{
+				[]interface{}{int64(COLUMN_SCALAR), "name"},
+				[]interface{}{int64(COLUMN_SCALAR), "score"},
+			},
+			[]interface{}{
+				[]interface{}{
+					[]interface{}{int64(VALUE_STRING), "John Doe"},
+					[]interface{}{int64(VALUE_DOUBLE), 2.5},
+				},
+			},
+			[]interface{}{"Cached execution: 0", "Query internal execution time: 0.1 milliseconds"},
+		}
+	}
+
+	res, err := QueryResultNew(nil, reply())
+	assert.Nil(t, err)
+	assert.True(t, res.Next())
+	assert.Equal(t, "John Doe", res.Record().GetByIndex(0))
+	assert.Equal(t, 2.5, res.Record().GetByIndex(1))
+	assert.Equal(t, 0.1, res.InternalExecutionTime())
+
+	var cmds []string
+	g := GraphNewWithExecutor("social", executorFunc(func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+		cmds = append(cmds, cmd)
+		if args[1] == "RETURN error" {
+			return nil, redis.Error("Invalid query")
+		}
+		return reply(), nil
+	}))
+	res, err = g.ROQuery("RETURN 1")
+	assert.Nil(t, err)
+	assert.True(t, res.Next())
+	assert.Equal(t, "John Doe", res.Record().GetByIndex(0))
+
+	results, err := g.Pipeline().Query("RETURN 1", nil, nil).ROQuery("RETURN error", nil, nil).Exec()
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, 1, len(results[0].Result.results))
+	assert.Equal(t, redis.Error("Invalid query"), results[1].Err)
+	assert.Equal(t, []string{"GRAPH.RO_QUERY", "GRAPH.QUERY", "GRAPH.RO_QUERY"}, cmds)
+
+	_, err = g.Begin()
+	assert.Equal(t, ErrConnRequired, err)
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return &fakeConn{handle: func(cmd string, args []interface{}) interface{} {
+			return fakeReply("redigo")
+		}}, nil
+	}}
+	g = GraphNewWithExecutor("social", RedigoExecutorNew(pool))
+	res, err = g.Query("RETURN 1")
+	assert.Nil(t, err)
+	assert.True(t, res.Next())
+	assert.Equal(t, "redigo", res.Record().GetByIndex(0))
+}
+
+func TestSchemaRefreshContext(t *testing.T) {
+	type ctxKey struct{}
+	nodeReply := func() interface{} {
+		return []interface{}{
+			[]interface{}{[]interface{}{int64(COLUMN_SCALAR), []byte("n")}},
+			[]interface{}{[]interface{}{[]interface{}{int64(VALUE_NODE), []interface{}{int64(1), []interface{}{int64(0)}, []interface{}{}}}}},
+			[]interface{}{},
+		}
+	}
+	var refreshes int
+	exec := executorFunc(func(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if args[1] != "CALL db.labels()" {
+			return nodeReply(), nil
+		}
+		refreshes++
+		assert.Equal(t, "GRAPH.RO_QUERY", cmd)
+		assert.Equal(t, "query", ctx.Value(ctxKey{}), "Expecting the refresh to run under the query's context")
+		assert.Equal(t, "timeout", args[len(args)-2], "Expecting the refresh to derive its timeout from the context")
+		return fakeReply("Person"), nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "query"), time.Minute)
+	defer cancel()
+	g := GraphNewWithExecutor("social", exec)
+	res, err := g.QueryContext(ctx, "MATCH (n) RETURN n", nil, NewQueryOptions().SetTimeoutFromContext(true))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, refreshes)
+	res.Next()
+	assert.Equal(t, []string{"Person"}, res.Record().GetByIndex(0).(*Node).Labels)
+
+	// Lazy results refresh under the query's context as well.
+	g = GraphNewWithExecutor("social", exec)
+	res, err = g.QueryContext(ctx, "MATCH (n) RETURN n", nil, NewQueryOptions().SetLazy(true))
+	assert.Nil(t, err)
+	cancel()
+	assert.False(t, res.Next())
+	assert.Equal(t, context.Canceled, res.Err())
+	assert.Equal(t, 1, refreshes)
+}
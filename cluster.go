@@ -0,0 +1,634 @@
+package redisgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// clusterSlots is the number of hash slots keys are distributed over.
+const clusterSlots = 16384
+
+// maxClusterRedirects bounds the MOVED and ASK redirections followed by a command.
+const maxClusterRedirects = 5
+
+// ErrTooManyRedirects is returned when a command keeps being redirected
+// between cluster nodes, e.g. while slots are migrating.
+var ErrTooManyRedirects = errors.New("redisgraph: too many cluster redirections")
+
+// Cluster is a ConnProvider routing commands across the nodes of a Redis
+// Cluster. Each command is sent to the node serving the hash slot of its
+// key, the graph's Id for graph commands, following MOVED and ASK
+// redirections. The slot map is loaded on first use and refreshed whenever a
+// redirection or connection failure suggests the topology changed.
+//
+// Commands pipelined over a single connection, such as those of a Pipeline
+// or Tx, are sent to the node serving the first key among them and must
+// therefore target keys of the same slot. Likewise GRAPH.COPY is rejected
+// unless both graphs hash to the same slot. GRAPH.LIST and GRAPH.CONFIG SET
+// are sent to every master, the graphs listed being merged. A Cluster is
+// safe for concurrent use.
+type Cluster struct {
+	seeds      []string
+	dial       func(addr string) (redis.Conn, error)
+	mutex      sync.RWMutex
+	slots      []string               // Node address by slot, empty until loaded.
+	pools      map[string]*redis.Pool // Connection pool by node address.
+	refreshing int32                  // Set while a background refresh is running.
+}
+
+// ClusterNew returns a Cluster discovering its topology from any of seeds,
+// node addresses in host:port form. dial connects to a node, applying any
+// authentication or timeouts required.
+func ClusterNew(seeds []string, dial func(addr string) (redis.Conn, error)) *Cluster {
+	return &Cluster{
+		seeds: seeds,
+		dial:  dial,
+		pools: make(map[string]*redis.Pool),
+	}
+}
+
+// Get returns a connection routing each command to the appropriate node.
+// Connections to nodes are borrowed as needed and returned once the
+// connection is closed.
+func (c *Cluster) Get() redis.Conn {
+	return &clusterConn{cluster: c, conns: make(map[string]redis.Conn)}
+}
+
+// Refresh reloads the slot map from the first node able to provide it.
+func (c *Cluster) Refresh() error {
+	c.mutex.RLock()
+	addrs := append([]string(nil), c.seeds...)
+	for addr := range c.pools {
+		if !containsString(addrs, addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	c.mutex.RUnlock()
+
+	err := errors.New("redisgraph: no cluster node to load slots from")
+	for _, addr := range addrs {
+		var slots []string
+		if slots, err = c.loadSlots(addr); err == nil {
+			c.mutex.Lock()
+			c.slots = slots
+			c.mutex.Unlock()
+			return nil
+		}
+	}
+	return err
+}
+
+// loadSlots issues CLUSTER SLOTS against the node at addr.
+func (c *Cluster) loadSlots(addr string) ([]string, error) {
+	conn := c.pool(addr).Get()
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+
+	slots := make([]string, clusterSlots)
+	for _, entry := range r {
+		// [start, end, [host, port, id], replicas...]
+		e, err := redis.Values(entry, nil)
+		if err != nil || len(e) < 3 {
+			return nil, newParseError("malformed cluster slots", entry, err)
+		}
+		start, err1 := redis.Int(e[0], nil)
+		end, err2 := redis.Int(e[1], nil)
+		master, err3 := redis.Values(e[2], nil)
+		if err1 != nil || err2 != nil || err3 != nil || len(master) < 2 ||
+			start < 0 || end >= clusterSlots || start > end {
+			return nil, newParseError("malformed cluster slots", entry, nil)
+		}
+		ip, err1 := redis.String(master[0], nil)
+		port, err2 := redis.Int(master[1], nil)
+		if err1 != nil || err2 != nil {
+			return nil, newParseError("malformed cluster node", master, nil)
+		}
+		// An empty host stands for the node which was queried.
+		if ip == "" {
+			ip = host
+		}
+		node := net.JoinHostPort(ip, strconv.Itoa(port))
+		for s := start; s <= end; s++ {
+			slots[s] = node
+		}
+	}
+	return slots, nil
+}
+
+// refreshAsync reloads the slot map in the background, unless a refresh is
+// already running.
+func (c *Cluster) refreshAsync() {
+	if !atomic.CompareAndSwapInt32(&c.refreshing, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&c.refreshing, 0)
+		c.Refresh()
+	}()
+}
+
+// nodeFor returns the address of the node serving key, or of any known node
+// should key be nil or its slot be unknown.
+func (c *Cluster) nodeFor(key interface{}) string {
+	c.mutex.RLock()
+	loaded := c.slots != nil
+	c.mutex.RUnlock()
+	if !loaded {
+		c.Refresh()
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if key != nil && c.slots != nil {
+		if addr := c.slots[Slot(keyString(key))]; addr != "" {
+			return addr
+		}
+	}
+	for _, addr := range c.slots {
+		if addr != "" {
+			return addr
+		}
+	}
+	if len(c.seeds) == 0 {
+		return ""
+	}
+	return c.seeds[0]
+}
+
+// masters returns the addresses of the nodes serving slots, or of any known
+// node should the slot map be unknown.
+func (c *Cluster) masters() []string {
+	fallback := c.nodeFor(nil)
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var addrs []string
+	for _, addr := range c.slots {
+		if addr != "" && !containsString(addrs, addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 && fallback != "" {
+		addrs = append(addrs, fallback)
+	}
+	return addrs
+}
+
+// moved records that slot is now served by the node at addr.
+func (c *Cluster) moved(slot int, addr string) {
+	c.mutex.Lock()
+	if c.slots == nil {
+		c.slots = make([]string, clusterSlots)
+	}
+	c.slots[slot] = addr
+	c.mutex.Unlock()
+	c.refreshAsync()
+}
+
+// pool returns the connection pool of the node at addr.
+func (c *Cluster) pool(addr string) *redis.Pool {
+	c.mutex.RLock()
+	p, ok := c.pools[addr]
+	c.mutex.RUnlock()
+	if ok {
+		return p
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if p, ok = c.pools[addr]; !ok {
+		p = &redis.Pool{
+			Dial:        func() (redis.Conn, error) { return c.dial(addr) },
+			MaxIdle:     8,
+			IdleTimeout: 4 * time.Minute,
+		}
+		c.pools[addr] = p
+	}
+	return p
+}
+
+// Close closes the connection pools of every node.
+func (c *Cluster) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var err error
+	for addr, p := range c.pools {
+		if e := p.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(c.pools, addr)
+	}
+	return err
+}
+
+// Slot returns the hash slot of key, honoring hash tags: only the part of
+// key between the first { and the following } is hashed, if not empty.
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 computes the CRC16/XMODEM checksum Redis Cluster hashes keys with.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	}
+	return fmt.Sprint(key)
+}
+
+// keylessCommands take no key, or none worth routing by.
+var keylessCommands = map[string]bool{
+	"ASKING": true, "CLUSTER": true, "DISCARD": true, "ECHO": true, "EXEC": true,
+	"GRAPH.CONFIG": true, "GRAPH.LIST": true, "INFO": true, "MULTI": true,
+	"PING": true, "READONLY": true, "UNWATCH": true,
+}
+
+// commandKey returns the key cmd is routed by, nil for keyless commands.
+func commandKey(cmd string, args []interface{}) interface{} {
+	if len(args) == 0 || keylessCommands[strings.ToUpper(cmd)] {
+		return nil
+	}
+	return args[0]
+}
+
+// checkSlots rejects cmd when its keys hash to different slots, which the
+// cluster would fail with a CROSSSLOT error.
+func checkSlots(cmd string, args []interface{}) error {
+	if !strings.EqualFold(cmd, "GRAPH.COPY") || len(args) < 2 {
+		return nil
+	}
+	src, dst := keyString(args[0]), keyString(args[1])
+	if Slot(src) != Slot(dst) {
+		return fmt.Errorf("redisgraph: can not copy graph %q to %q, they hash to different cluster slots; use a common hash tag, e.g. {tenant}.src and {tenant}.dst", src, dst)
+	}
+	return nil
+}
+
+// redirection parses MOVED and ASK errors.
+func redirection(err error) (ask bool, slot int, addr string, ok bool) {
+	e, isRedis := err.(redis.Error)
+	if !isRedis {
+		return false, 0, "", false
+	}
+	f := strings.Fields(string(e))
+	if len(f) != 3 || (f[0] != "MOVED" && f[0] != "ASK") {
+		return false, 0, "", false
+	}
+	slot, convErr := strconv.Atoi(f[1])
+	if convErr != nil || slot < 0 || slot >= clusterSlots {
+		return false, 0, "", false
+	}
+	return f[0] == "ASK", slot, f[2], true
+}
+
+type clusterCommand struct {
+	addr string
+	cmd  string
+	args []interface{}
+}
+
+// clusterConn is a connection routing commands to cluster nodes, see Cluster.
+type clusterConn struct {
+	cluster *Cluster
+	conns   map[string]redis.Conn // Connections borrowed so far, by node address.
+	last    string                // Node keyless commands are sent to.
+	queued  []clusterCommand      // Commands sent but not yet written to a node.
+	pending []clusterCommand      // Commands written whose reply was not received.
+	err     error
+}
+
+func (cc *clusterConn) conn(addr string) redis.Conn {
+	conn, ok := cc.conns[addr]
+	if !ok {
+		conn = cc.cluster.pool(addr).Get()
+		cc.conns[addr] = conn
+	}
+	return conn
+}
+
+// failed notes a connection failure, which may be caused by a node leaving
+// the cluster.
+func (cc *clusterConn) failed(err error) {
+	if _, ok := err.(redis.Error); !ok && err != nil {
+		cc.cluster.refreshAsync()
+	}
+}
+
+func (cc *clusterConn) Close() error {
+	var err error
+	for addr, conn := range cc.conns {
+		if e := conn.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(cc.conns, addr)
+	}
+	cc.queued, cc.pending = nil, nil
+	if cc.err == nil {
+		cc.err = errors.New("redisgraph: connection closed")
+	}
+	return err
+}
+
+func (cc *clusterConn) Err() error {
+	if cc.err != nil {
+		return cc.err
+	}
+	for _, conn := range cc.conns {
+		if err := conn.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cc *clusterConn) Send(cmd string, args ...interface{}) error {
+	if cc.err != nil {
+		return cc.err
+	}
+	if err := checkSlots(cmd, args); err != nil {
+		return err
+	}
+	cc.queued = append(cc.queued, clusterCommand{cmd: cmd, args: args})
+	return nil
+}
+
+// route returns the address of the node serving key, keyless commands are
+// sent to the node the previous command was sent to.
+func (cc *clusterConn) route(key interface{}) string {
+	if key != nil || cc.last == "" {
+		cc.last = cc.cluster.nodeFor(key)
+	}
+	return cc.last
+}
+
+// write sends queued commands, along with extra, to the node serving the
+// first key among them and returns that node's address.
+func (cc *clusterConn) write(extra *clusterCommand) (string, error) {
+	var key interface{}
+	cmds := cc.queued
+	if extra != nil {
+		cmds = append(cmds, *extra)
+	}
+	for _, c := range cmds {
+		if key = commandKey(c.cmd, c.args); key != nil {
+			break
+		}
+	}
+	addr := cc.route(key)
+	conn := cc.conn(addr)
+	for _, c := range cc.queued {
+		if err := conn.Send(c.cmd, c.args...); err != nil {
+			cc.failed(err)
+			return "", err
+		}
+		c.addr = addr
+		cc.pending = append(cc.pending, c)
+	}
+	cc.queued = nil
+	return addr, nil
+}
+
+func (cc *clusterConn) Flush() error {
+	if cc.err != nil {
+		return cc.err
+	}
+	if _, err := cc.write(nil); err != nil {
+		return err
+	}
+	for _, conn := range cc.conns {
+		if err := conn.Flush(); err != nil {
+			cc.failed(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (cc *clusterConn) Receive() (interface{}, error) {
+	return cc.receive(context.Background())
+}
+
+func (cc *clusterConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return cc.receive(ctx)
+}
+
+func (cc *clusterConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return cc.receive(ctx)
+}
+
+func (cc *clusterConn) receive(ctx context.Context) (interface{}, error) {
+	if cc.err != nil {
+		return nil, cc.err
+	}
+	if len(cc.queued) > 0 {
+		if err := cc.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if len(cc.pending) == 0 {
+		return nil, errors.New("redisgraph: no pending reply to receive")
+	}
+
+	c := cc.pending[0]
+	cc.pending = cc.pending[1:]
+	r, err := receiveContext(ctx, cc.conn(c.addr))
+	if _, _, _, ok := redirection(err); ok {
+		// Reissue the redirected command on its own.
+		return cc.redirect(ctx, err, c.cmd, c.args)
+	}
+	cc.failed(err)
+	return r, err
+}
+
+func (cc *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return cc.do(context.Background(), cmd, args...)
+}
+
+func (cc *clusterConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return cc.do(ctx, cmd, args...)
+}
+
+func (cc *clusterConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return cc.do(ctx, cmd, args...)
+}
+
+func (cc *clusterConn) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if cc.err != nil {
+		return nil, cc.err
+	}
+	if err := checkSlots(cmd, args); err != nil {
+		return nil, err
+	}
+
+	// A lone command is free to follow redirections.
+	if len(cc.queued) == 0 && len(cc.pending) == 0 {
+		if cmd == "" {
+			return nil, nil
+		}
+		if strings.EqualFold(cmd, "GRAPH.LIST") {
+			return cc.listAll(ctx)
+		}
+		if strings.EqualFold(cmd, "GRAPH.CONFIG") && len(args) > 0 && strings.EqualFold(keyString(args[0]), "SET") {
+			return cc.configSetAll(ctx, args)
+		}
+		addr := cc.route(commandKey(cmd, args))
+		r, err := doContext(ctx, cc.conn(addr), cmd, args...)
+		if _, _, _, ok := redirection(err); ok {
+			return cc.redirect(ctx, err, cmd, args)
+		}
+		cc.failed(err)
+		return r, err
+	}
+
+	// Otherwise the command completes a pipeline, e.g. EXEC, whose replies
+	// are consumed as by redis.Conn's Do.
+	var extra *clusterCommand
+	if cmd != "" {
+		extra = &clusterCommand{cmd: cmd, args: args}
+	}
+	addr, err := cc.write(extra)
+	if err != nil {
+		return nil, err
+	}
+	for a, conn := range cc.conns {
+		if a != addr {
+			// Replies pending on other nodes are consumed first.
+			for _, p := range cc.pending {
+				if p.addr == a {
+					receiveContext(ctx, conn)
+				}
+			}
+		}
+	}
+	cc.pending = nil
+
+	r, err := doContext(ctx, cc.conn(addr), cmd, args...)
+	if ask, slot, target, ok := redirection(err); ok && !ask {
+		// The pipeline can not be replayed safely, but later ones will
+		// be routed correctly.
+		cc.cluster.moved(slot, target)
+	}
+	cc.failed(err)
+	return r, err
+}
+
+// broadcast issues cmd against every master, returning their replies. Every
+// master is attempted, the first error met is reported.
+func (cc *clusterConn) broadcast(ctx context.Context, cmd string, args ...interface{}) ([]interface{}, error) {
+	var replies []interface{}
+	var err error
+	for _, addr := range cc.cluster.masters() {
+		r, e := doContext(ctx, cc.conn(addr), cmd, args...)
+		cc.failed(e)
+		if e != nil && err == nil {
+			err = e
+		}
+		replies = append(replies, r)
+	}
+	return replies, err
+}
+
+// listAll issues GRAPH.LIST against every master, each listing only the
+// graphs it stores, and merges their replies.
+func (cc *clusterConn) listAll(ctx context.Context) (interface{}, error) {
+	replies, err := cc.broadcast(ctx, "GRAPH.LIST")
+	if err != nil {
+		return nil, err
+	}
+	graphs := []interface{}{}
+	for _, r := range replies {
+		names, err := redis.Values(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		graphs = append(graphs, names...)
+	}
+	return graphs, nil
+}
+
+// configSetAll issues GRAPH.CONFIG SET against every master, so that shards
+// share the same configuration.
+func (cc *clusterConn) configSetAll(ctx context.Context, args []interface{}) (interface{}, error) {
+	replies, err := cc.broadcast(ctx, "GRAPH.CONFIG", args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, errors.New("redisgraph: no cluster node to configure")
+	}
+	return replies[0], nil
+}
+
+// redirect follows the redirection err, and any subsequent one, reissuing cmd.
+func (cc *clusterConn) redirect(ctx context.Context, err error, cmd string, args []interface{}) (interface{}, error) {
+	for i := 0; i < maxClusterRedirects; i++ {
+		ask, slot, addr, _ := redirection(err)
+		conn := cc.conn(addr)
+
+		var r interface{}
+		if ask {
+			// The slot is being migrated, the key is only served by addr
+			// for this very command.
+			if err = conn.Send("ASKING"); err != nil {
+				cc.failed(err)
+				return nil, err
+			}
+		} else {
+			cc.cluster.moved(slot, addr)
+		}
+		r, err = doContext(ctx, conn, cmd, args...)
+		if _, _, _, ok := redirection(err); !ok {
+			cc.failed(err)
+			return r, err
+		}
+	}
+	return nil, ErrTooManyRedirects
+}
+
+// receiveContext receives a reply from conn, honoring ctx if conn supports it.
+func receiveContext(ctx context.Context, conn redis.Conn) (interface{}, error) {
+	if _, ok := conn.(redis.ConnWithContext); ok && ctx.Done() != nil {
+		return redis.ReceiveContext(conn, ctx)
+	}
+	return conn.Receive()
+}
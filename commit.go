@@ -0,0 +1,374 @@
+package redisgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrEntityNotFound is returned by Commit when a committed entity it has to
+// update or delete no longer exists on the server, nothing is committed then.
+var ErrEntityNotFound = errors.New("redisgraph: committed entity no longer exists")
+
+// Commit persists the changes made to the graph since the last commit: new
+// nodes and edges are created, modified properties and labels are updated and
+// removed entities are deleted. Entities which already exist on the server,
+// either committed earlier or retrieved by a query, are matched by their ID.
+//
+// Commit assigns server IDs to the entities it creates. The returned result
+// holds the commit's statistics, it is empty when there was nothing to commit.
+func (g *Graph) Commit() (*QueryResult, error) {
+	return g.CommitContext(context.Background())
+}
+
+// CommitContext persists the changes made to the graph since the last commit, honoring ctx.
+func (g *Graph) CommitContext(ctx context.Context) (*QueryResult, error) {
+	cq, err := g.buildCommit()
+	if err != nil {
+		return nil, err
+	}
+	if cq.empty() {
+		return newQueryResult(g), nil
+	}
+
+	res, err := g.QueryContext(ctx, cq.String(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// An entity matched by ID no longer exists when no row is produced,
+	// in which case none of the clauses took effect.
+	if len(res.results) == 0 {
+		return nil, ErrEntityNotFound
+	}
+	row := res.results[0].Values()
+	if len(cq.created) == 0 {
+		if n, _ := toInt64(row[0]); n == 0 {
+			return nil, ErrEntityNotFound
+		}
+	}
+
+	for i, entity := range cq.created {
+		id, ok := toInt64(row[i])
+		if !ok || id < 0 {
+			return nil, newParseError("malformed entity ID", row[i], nil)
+		}
+		switch entity := entity.(type) {
+		case *Node:
+			entity.ID = uint64(id)
+		case *Edge:
+			entity.ID = uint64(id)
+		}
+	}
+
+	for _, n := range g.Nodes {
+		n.markCommitted()
+	}
+	for _, e := range g.Edges {
+		e.markCommitted()
+	}
+	g.removedNodes = nil
+	g.removedEdges = nil
+
+	// The returned IDs are an implementation detail of the commit.
+	res.results = nil
+	res.header = newQueryResult(g).header
+	return res, nil
+}
+
+// RemoveNode removes a node, along with its edges, from the graph. A node
+// which has been committed is deleted from the server by the next commit.
+func (g *Graph) RemoveNode(n *Node) {
+	if g.Nodes[n.Alias] != n {
+		return
+	}
+	delete(g.Nodes, n.Alias)
+
+	edges := g.Edges[:0]
+	for _, e := range g.Edges {
+		if e.Source == n || e.Destination == n {
+			continue
+		}
+		if n.persisted && e.Source == nil && (e.srcNodeID == n.ID || e.destNodeID == n.ID) {
+			continue
+		}
+		edges = append(edges, e)
+	}
+	g.Edges = edges
+
+	if n.persisted {
+		// The server deletes the node's edges along with it.
+		g.removedNodes = append(g.removedNodes, n)
+	}
+}
+
+// RemoveEdge removes an edge from the graph. An edge which has been
+// committed is deleted from the server by the next commit.
+func (g *Graph) RemoveEdge(e *Edge) {
+	for i, edge := range g.Edges {
+		if edge == e {
+			g.Edges = append(g.Edges[:i], g.Edges[i+1:]...)
+			if e.persisted {
+				g.removedEdges = append(g.removedEdges, e)
+			}
+			return
+		}
+	}
+}
+
+// forgetCommitted marks every entity held by the graph as new, e.g. once the
+// graph has been deleted from the server.
+func (g *Graph) forgetCommitted() {
+	for _, n := range g.Nodes {
+		n.persisted = false
+		n.committed = nil
+		n.committedLabels = nil
+	}
+	for _, e := range g.Edges {
+		e.persisted = false
+		e.committed = nil
+	}
+	g.removedNodes = nil
+	g.removedEdges = nil
+}
+
+// buildCommit collects the clauses required to bring the server up to date
+// with the graph's local state.
+func (g *Graph) buildCommit() (*commitQuery, error) {
+	cq := &commitQuery{matched: make(map[string]bool)}
+
+	// Visit nodes in a stable order so identical commits share the query cache.
+	aliases := make([]string, 0, len(g.Nodes))
+	for alias := range g.Nodes {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		n := g.Nodes[alias]
+		if !n.persisted {
+			s, err := n.encode(EncodeValue)
+			if err != nil {
+				return nil, err
+			}
+			cq.create(s, n.Alias, n)
+			continue
+		}
+
+		changes := len(cq.sets) + len(cq.removes)
+		diffLabels(cq, n.Alias, n.committedLabels, n.Labels)
+		if err := diffProperties(cq, n.Alias, n.committed, n.Properties); err != nil {
+			return nil, err
+		}
+		if len(cq.sets)+len(cq.removes) > changes {
+			cq.matchNode(n.Alias, n.ID)
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.alias == "" {
+			e.alias = RandomString(10)
+		}
+		if !e.persisted {
+			for _, n := range []*Node{e.Source, e.Destination} {
+				if n.persisted {
+					cq.matchNode(n.Alias, n.ID)
+				}
+			}
+			s, err := e.encode(e.alias, EncodeValue)
+			if err != nil {
+				return nil, err
+			}
+			cq.create(s, e.alias, e)
+			continue
+		}
+
+		changes := len(cq.sets)
+		if err := diffProperties(cq, e.alias, e.committed, e.Properties); err != nil {
+			return nil, err
+		}
+		if len(cq.sets) > changes {
+			cq.matchEdge(e.alias, e.ID)
+		}
+	}
+
+	deleted := make(map[uint64]bool, len(g.removedNodes))
+	for _, n := range g.removedNodes {
+		// The node's alias may since have been reused by a new node.
+		alias := RandomString(10)
+		cq.matchNode(alias, n.ID)
+		cq.deletes = append(cq.deletes, alias)
+		deleted[n.ID] = true
+	}
+	for _, e := range g.removedEdges {
+		if deleted[e.SourceNodeID()] || deleted[e.DestNodeID()] {
+			// Deleted along with its endpoint.
+			continue
+		}
+		cq.matchEdge(e.alias, e.ID)
+		cq.deletes = append(cq.deletes, e.alias)
+	}
+
+	return cq, nil
+}
+
+// commitQuery accumulates the clauses of a commit.
+type commitQuery struct {
+	matches []string
+	matched map[string]bool // Aliases bound by matches.
+	creates []string
+	sets    []string
+	removes []string
+	deletes []string
+	returns []string
+	created []interface{} // Entity whose ID is reported by each returned column.
+}
+
+func (cq *commitQuery) empty() bool {
+	return len(cq.creates)+len(cq.sets)+len(cq.removes)+len(cq.deletes) == 0
+}
+
+func (cq *commitQuery) create(pattern string, alias string, entity interface{}) {
+	cq.creates = append(cq.creates, pattern)
+	cq.returns = append(cq.returns, fmt.Sprintf("id(%s)", alias))
+	cq.created = append(cq.created, entity)
+}
+
+func (cq *commitQuery) matchNode(alias string, id uint64) {
+	if !cq.matched[alias] {
+		cq.matched[alias] = true
+		cq.matches = append(cq.matches, fmt.Sprintf("MATCH (%s) WHERE id(%s) = %d", alias, alias, id))
+	}
+}
+
+func (cq *commitQuery) matchEdge(alias string, id uint64) {
+	if !cq.matched[alias] {
+		cq.matched[alias] = true
+		cq.matches = append(cq.matches, fmt.Sprintf("MATCH ()-[%s]->() WHERE id(%s) = %d", alias, alias, id))
+	}
+}
+
+func (cq *commitQuery) String() string {
+	clauses := append([]string(nil), cq.matches...)
+	if len(cq.creates) > 0 {
+		clauses = append(clauses, "CREATE "+strings.Join(cq.creates, ","))
+	}
+	if len(cq.sets) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(cq.sets, ","))
+	}
+	if len(cq.removes) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(cq.removes, ","))
+	}
+	if len(cq.deletes) > 0 {
+		clauses = append(clauses, "DELETE "+strings.Join(cq.deletes, ","))
+	}
+	if len(cq.returns) > 0 {
+		clauses = append(clauses, "RETURN "+strings.Join(cq.returns, ","))
+	} else {
+		// Produces a zero count when a matched entity no longer exists.
+		clauses = append(clauses, "RETURN count(*)")
+	}
+	return strings.Join(clauses, " ")
+}
+
+// diffLabels adds the clauses turning committed labels into current ones.
+func diffLabels(cq *commitQuery, alias string, committed, current []string) {
+	for _, l := range current {
+		if !containsString(committed, l) {
+			cq.sets = append(cq.sets, alias+":"+l)
+		}
+	}
+	for _, l := range committed {
+		if !containsString(current, l) {
+			cq.removes = append(cq.removes, alias+":"+l)
+		}
+	}
+}
+
+// diffProperties adds the clauses turning committed properties into current
+// ones, removed properties are set to null.
+func diffProperties(cq *commitQuery, alias string, committed, current map[string]interface{}) error {
+	keys := make([]string, 0, len(current))
+	for k := range current {
+		keys = append(keys, k)
+	}
+	for k := range committed {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, ok := current[k]
+		old, had := committed[k]
+		if ok == had && reflect.DeepEqual(v, old) {
+			continue
+		}
+		s, err := EncodeValue(v)
+		if err != nil {
+			return err
+		}
+		cq.sets = append(cq.sets, fmt.Sprintf("%s.%s = %s", alias, quoteIdentifier(k), s))
+	}
+	return nil
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// markCommitted records the node's current state as the one stored on the server.
+func (n *Node) markCommitted() {
+	n.persisted = true
+	n.committed = copyProperties(n.Properties)
+	n.committedLabels = append([]string(nil), n.Labels...)
+}
+
+// markCommitted records the edge's current state as the one stored on the server.
+func (e *Edge) markCommitted() {
+	e.persisted = true
+	e.committed = copyProperties(e.Properties)
+	if e.Source != nil && e.Destination != nil {
+		e.srcNodeID = e.Source.ID
+		e.destNodeID = e.Destination.ID
+	}
+}
+
+// copyProperties copies properties deep enough for in-place modifications of
+// list and map values to be detected.
+func copyProperties(properties map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(properties))
+	for k, v := range properties {
+		c[k] = copyValue(v)
+	}
+	return c
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i := range v {
+			c[i] = copyValue(v[i])
+		}
+		return c
+	case map[string]interface{}:
+		return copyProperties(v)
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && !rv.IsNil() {
+		c := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		reflect.Copy(c, rv)
+		return c.Interface()
+	}
+	return v
+}
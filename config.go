@@ -0,0 +1,197 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Configuration settings exposed by GRAPH.CONFIG.
+const (
+	CONFIG_THREAD_COUNT              string = "THREAD_COUNT"
+	CONFIG_CACHE_SIZE                string = "CACHE_SIZE"
+	CONFIG_OMP_THREAD_COUNT          string = "OMP_THREAD_COUNT"
+	CONFIG_NODE_CREATION_BUFFER      string = "NODE_CREATION_BUFFER"
+	CONFIG_MAX_QUEUED_QUERIES        string = "MAX_QUEUED_QUERIES"
+	CONFIG_TIMEOUT                   string = "TIMEOUT"
+	CONFIG_TIMEOUT_DEFAULT           string = "TIMEOUT_DEFAULT"
+	CONFIG_TIMEOUT_MAX               string = "TIMEOUT_MAX"
+	CONFIG_RESULTSET_SIZE            string = "RESULTSET_SIZE"
+	CONFIG_QUERY_MEM_CAPACITY        string = "QUERY_MEM_CAPACITY"
+	CONFIG_VKEY_MAX_ENTITY_COUNT     string = "VKEY_MAX_ENTITY_COUNT"
+	CONFIG_DELTA_MAX_PENDING_CHANGES string = "DELTA_MAX_PENDING_CHANGES"
+)
+
+// configSetting describes a known configuration setting.
+type configSetting struct {
+	runtime bool  // Whether the setting can be modified at run-time.
+	min     int64 // Smallest valid value.
+}
+
+var configSettings = map[string]configSetting{
+	CONFIG_THREAD_COUNT:              {runtime: false, min: 1},
+	CONFIG_CACHE_SIZE:                {runtime: false, min: 0},
+	CONFIG_OMP_THREAD_COUNT:          {runtime: false, min: 1},
+	CONFIG_NODE_CREATION_BUFFER:      {runtime: false, min: 128},
+	CONFIG_MAX_QUEUED_QUERIES:        {runtime: true, min: 1},
+	CONFIG_TIMEOUT:                   {runtime: true, min: 0},
+	CONFIG_TIMEOUT_DEFAULT:           {runtime: true, min: 0},
+	CONFIG_TIMEOUT_MAX:               {runtime: true, min: 0},
+	CONFIG_RESULTSET_SIZE:            {runtime: true, min: -1},
+	CONFIG_QUERY_MEM_CAPACITY:        {runtime: true, min: 0},
+	CONFIG_VKEY_MAX_ENTITY_COUNT:     {runtime: true, min: 1},
+	CONFIG_DELTA_MAX_PENDING_CHANGES: {runtime: true, min: 1},
+}
+
+// Config holds the module's configuration as reported by GRAPH.CONFIG GET.
+// Settings not reported by the server are left zero.
+type Config struct {
+	ThreadCount            int64 `redisgraph:"THREAD_COUNT"`
+	CacheSize              int64 `redisgraph:"CACHE_SIZE"`
+	OMPThreadCount         int64 `redisgraph:"OMP_THREAD_COUNT"`
+	NodeCreationBuffer     int64 `redisgraph:"NODE_CREATION_BUFFER"`
+	MaxQueuedQueries       int64 `redisgraph:"MAX_QUEUED_QUERIES"`
+	Timeout                int64 `redisgraph:"TIMEOUT"`
+	TimeoutDefault         int64 `redisgraph:"TIMEOUT_DEFAULT"`
+	TimeoutMax             int64 `redisgraph:"TIMEOUT_MAX"`
+	ResultSetSize          int64 `redisgraph:"RESULTSET_SIZE"`
+	QueryMemCapacity       int64 `redisgraph:"QUERY_MEM_CAPACITY"`
+	VKeyMaxEntityCount     int64 `redisgraph:"VKEY_MAX_ENTITY_COUNT"`
+	DeltaMaxPendingChanges int64 `redisgraph:"DELTA_MAX_PENDING_CHANGES"`
+
+	// Settings holds every reported setting by name, including those not
+	// mapped onto a field above.
+	Settings map[string]interface{} `redisgraph:"-"`
+}
+
+// Config retrieves the module's configuration.
+func (g *Graph) Config() (*Config, error) {
+	return g.ConfigContext(context.Background())
+}
+
+// ConfigContext retrieves the module's configuration, honoring ctx.
+func (g *Graph) ConfigContext(ctx context.Context) (*Config, error) {
+	r, err := g.do(ctx, "GRAPH.CONFIG", "GET", "*")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := parseConfigPairs(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{Settings: settings}
+	if err := assignStruct(reflect.ValueOf(c).Elem(), settings); err != nil {
+		return nil, newParseError("malformed configuration", r, err)
+	}
+	return c, nil
+}
+
+// ConfigGet retrieves a single configuration setting, integer settings are
+// reported as int64.
+func (g *Graph) ConfigGet(name string) (interface{}, error) {
+	return g.ConfigGetContext(context.Background(), name)
+}
+
+// ConfigGetContext retrieves a single configuration setting, honoring ctx.
+func (g *Graph) ConfigGetContext(ctx context.Context, name string) (interface{}, error) {
+	r, err := g.do(ctx, "GRAPH.CONFIG", "GET", name)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigSetting(r, name)
+}
+
+// parseConfigSetting parses the reply to GRAPH.CONFIG GET name.
+func parseConfigSetting(reply interface{}, name string) (interface{}, error) {
+	pair, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, newParseError("malformed configuration setting", reply, err)
+	}
+
+	// Some versions wrap the [name, value] pair within an array.
+	if len(pair) == 1 {
+		if _, nested := pair[0].([]interface{}); nested {
+			settings, err := parseConfigPairs(reply)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range settings {
+				if strings.EqualFold(k, name) {
+					return v, nil
+				}
+			}
+			return nil, fmt.Errorf("redisgraph: unknown configuration setting %q", name)
+		}
+	}
+
+	if len(pair) != 2 {
+		return nil, newParseError("malformed configuration setting", reply, nil)
+	}
+	return configValue(pair[1]), nil
+}
+
+// ConfigSet modifies a configuration setting at run-time. name must be a
+// known setting which can be modified at run-time and value an integer
+// within the setting's valid range. Over a Cluster, the setting is modified
+// on every master.
+func (g *Graph) ConfigSet(name string, value interface{}) error {
+	return g.ConfigSetContext(context.Background(), name, value)
+}
+
+// ConfigSetContext modifies a configuration setting at run-time, honoring ctx.
+func (g *Graph) ConfigSetContext(ctx context.Context, name string, value interface{}) error {
+	name = strings.ToUpper(name)
+	setting, ok := configSettings[name]
+	if !ok {
+		return fmt.Errorf("redisgraph: unknown configuration setting %q", name)
+	}
+	if !setting.runtime {
+		return fmt.Errorf("redisgraph: configuration setting %s can only be set at load time", name)
+	}
+	v, ok := toInt64(value)
+	if !ok {
+		return fmt.Errorf("redisgraph: configuration setting %s expects an integer, got %T", name, value)
+	}
+	if v < setting.min {
+		return fmt.Errorf("redisgraph: configuration setting %s must be at least %d, got %d", name, setting.min, v)
+	}
+
+	_, err := g.do(ctx, "GRAPH.CONFIG", "SET", name, v)
+	return err
+}
+
+// parseConfigPairs parses a [[name, value] X N] reply.
+func parseConfigPairs(reply interface{}) (map[string]interface{}, error) {
+	pairs, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, newParseError("malformed configuration", reply, err)
+	}
+
+	settings := make(map[string]interface{}, len(pairs))
+	for _, p := range pairs {
+		pair, err := redis.Values(p, nil)
+		if err != nil || len(pair) != 2 {
+			return nil, newParseError("malformed configuration setting", p, err)
+		}
+		name, err := redis.String(pair[0], nil)
+		if err != nil {
+			return nil, newParseError("malformed configuration setting name", p, err)
+		}
+		settings[name] = configValue(pair[1])
+	}
+	return settings, nil
+}
+
+// configValue converts a raw setting value, integers are reported as int64
+// and everything else as a string.
+func configValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
@@ -15,6 +15,9 @@ type Edge struct {
 	srcNodeID   uint64
 	destNodeID  uint64
 	graph       *Graph
+	alias       string                 // Identifies the edge within a commit.
+	persisted   bool                   // Set once the edge exists on the server.
+	committed   map[string]interface{} // Properties as of the last commit.
 }
 
 // EdgeNew create a new Edge
@@ -68,38 +71,44 @@ func (e Edge) String() string {
 		return "{}"
 	}
 
-	p := make([]string, 0, len(e.Properties))
-	for k, v := range e.Properties {
-		p = append(p, fmt.Sprintf("%s:%v", k, ToString(v)))
-	}
+	p, _ := formatProperties(e.Properties, encodeBestEffort)
+	return fmt.Sprintf("{%s}", p)
+}
 
-	s := fmt.Sprintf("{%s}", strings.Join(p, ","))
+// Encode returns the edge as a Cypher pattern.
+//
+// Deprecated: properties which can not be encoded are formatted by
+// fmt.Sprint instead, yielding invalid Cypher, use EncodePattern.
+func (e Edge) Encode() string {
+	s, _ := e.encode("", encodeBestEffort)
 	return s
 }
 
-// Encode makes Edge satisfy the Stringer interface
-func (e Edge) Encode() string {
+// EncodePattern returns the edge as a Cypher pattern, e.g.
+// (src)-[:TYPE {k:v}]->(dst), its endpoints referenced by alias.
+func (e Edge) EncodePattern() (string, error) {
+	return e.encode("", EncodeValue)
+}
+
+func (e Edge) encode(alias string, encode func(interface{}) (string, error)) (string, error) {
 	s := []string{"(", e.Source.Alias, ")"}
 
-	s = append(s, "-[")
+	s = append(s, "-[", alias)
 
 	if e.Relation != "" {
 		s = append(s, ":", e.Relation)
 	}
 
 	if len(e.Properties) > 0 {
-		p := make([]string, 0, len(e.Properties))
-		for k, v := range e.Properties {
-			p = append(p, fmt.Sprintf("%s:%v", k, ToString(v)))
+		p, err := formatProperties(e.Properties, encode)
+		if err != nil {
+			return "", err
 		}
-
-		s = append(s, "{")
-		s = append(s, strings.Join(p, ","))
-		s = append(s, "}")
+		s = append(s, "{", p, "}")
 	}
 
 	s = append(s, "]->")
 	s = append(s, "(", e.Destination.Alias, ")")
 
-	return strings.Join(s, "")
+	return strings.Join(s, ""), nil
 }
@@ -0,0 +1,27 @@
+package redisgraph
+
+import "fmt"
+
+// ParseError is returned when a reply received from the server can not be
+// decoded, Reply holds the offending raw reply for diagnostics.
+type ParseError struct {
+	Msg   string      // Description of what failed to parse.
+	Reply interface{} // Raw reply, or part of it, which failed to parse.
+	Err   error       // Underlying conversion error, may be nil.
+}
+
+func newParseError(msg string, reply interface{}, err error) *ParseError {
+	return &ParseError{Msg: msg, Reply: reply, Err: err}
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("redisgraph: %s: %v (reply: %v)", e.Msg, e.Err, e.Reply)
+	}
+	return fmt.Sprintf("redisgraph: %s (reply: %v)", e.Msg, e.Reply)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -46,6 +46,26 @@ func ExampleGraphNew_pool() {
 
 }
 
+func ExampleGraphNewWithPool() {
+	host := "localhost:6379"
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", host)
+	}}
+
+	// A connection is borrowed from the pool for every command,
+	// graph can safely be shared by multiple goroutines.
+	graph := redisgraph.GraphNewWithPool("social", pool)
+
+	q := "CREATE (w:WorkPlace {name:'RedisLabs'}) RETURN w"
+	res, _ := graph.Query(q)
+
+	res.Next()
+	r := res.Record()
+	w := r.GetByIndex(0).(*redisgraph.Node)
+	fmt.Println(w.Labels[0])
+	// Output: WorkPlace
+}
+
 func ExampleGraphNew_tls() {
 	// Consider the following helper methods that provide us with the connection details (host and password)
 	// and the paths for:
@@ -0,0 +1,224 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// planIndent is the number of spaces each level of an execution plan is indented by.
+const planIndent = 4
+
+var profileStats = regexp.MustCompile(`^Records produced: (\d+), Execution time: ([0-9.]+) ms$`)
+
+// Operation is a single operator within an execution plan.
+type Operation struct {
+	Name            string       // Operator name, e.g. "Node By Label Scan".
+	Args            []string     // Operator details, e.g. "(n:Person)".
+	RecordsProduced int          // Number of records produced, profiled plans only.
+	ExecutionTime   float64      // Execution time in milliseconds, profiled plans only.
+	Children        []*Operation // Operators feeding into this one.
+}
+
+// ExecutionPlan is the tree of operations a query is executed by,
+// as reported by GRAPH.EXPLAIN or GRAPH.PROFILE.
+type ExecutionPlan struct {
+	Root     *Operation
+	Profiled bool // Set when operations carry runtime statistics.
+}
+
+// ExecutionPlanNew builds an ExecutionPlan out of the lines reported by
+// GRAPH.EXPLAIN or GRAPH.PROFILE, each level of the tree is indented by
+// four spaces relative to its parent.
+func ExecutionPlanNew(lines []string) (*ExecutionPlan, error) {
+	plan := &ExecutionPlan{}
+	// Path from the root to the last operation parsed.
+	var stack []*Operation
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent%planIndent != 0 {
+			return nil, newParseError("malformed execution plan indentation", line, nil)
+		}
+		depth := indent / planIndent
+		if depth > len(stack) || (depth == 0 && plan.Root != nil) {
+			return nil, newParseError("malformed execution plan nesting", line, nil)
+		}
+
+		op, profiled, err := parseOperation(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if profiled {
+			plan.Profiled = true
+		}
+
+		if depth == 0 {
+			plan.Root = op
+		} else {
+			parent := stack[depth-1]
+			parent.Children = append(parent.Children, op)
+		}
+		stack = append(stack[:depth], op)
+	}
+
+	if plan.Root == nil {
+		return nil, newParseError("empty execution plan", lines, nil)
+	}
+	return plan, nil
+}
+
+// parseOperation parses a single, unindented, execution plan line of the form
+// "Name | arg | ... | Records produced: N, Execution time: T ms".
+func parseOperation(line string) (*Operation, bool, error) {
+	parts := strings.Split(line, " | ")
+	op := &Operation{Name: strings.TrimSpace(parts[0])}
+	profiled := false
+
+	if last := len(parts) - 1; last > 0 {
+		if m := profileStats.FindStringSubmatch(strings.TrimSpace(parts[last])); m != nil {
+			records, err := strconv.Atoi(m[1])
+			if err != nil {
+				return nil, false, newParseError("malformed records produced", line, err)
+			}
+			elapsed, err := strconv.ParseFloat(m[2], 64)
+			if err != nil {
+				return nil, false, newParseError("malformed execution time", line, err)
+			}
+			op.RecordsProduced = records
+			op.ExecutionTime = elapsed
+			profiled = true
+			parts = parts[:last]
+		}
+	}
+
+	for _, arg := range parts[1:] {
+		op.Args = append(op.Args, strings.TrimSpace(arg))
+	}
+	return op, profiled, nil
+}
+
+// Walk visits every operation of the plan depth first, starting at the root.
+func (p *ExecutionPlan) Walk(fn func(op *Operation, depth int)) {
+	var walk func(op *Operation, depth int)
+	walk = func(op *Operation, depth int) {
+		fn(op, depth)
+		for _, child := range op.Children {
+			walk(child, depth+1)
+		}
+	}
+	if p.Root != nil {
+		walk(p.Root, 0)
+	}
+}
+
+// Find returns all operations named name.
+func (p *ExecutionPlan) Find(name string) []*Operation {
+	var ops []*Operation
+	p.Walk(func(op *Operation, depth int) {
+		if op.Name == name {
+			ops = append(ops, op)
+		}
+	})
+	return ops
+}
+
+// Contains reports whether the plan holds an operation named name,
+// e.g. "Node By Index Scan".
+func (p *ExecutionPlan) Contains(name string) bool {
+	return len(p.Find(name)) > 0
+}
+
+// String returns the plan in the indented form reported by the server.
+func (p *ExecutionPlan) String() string {
+	var sb strings.Builder
+	p.Walk(func(op *Operation, depth int) {
+		sb.WriteString(strings.Repeat(" ", depth*planIndent))
+		sb.WriteString(op.format(p.Profiled))
+		sb.WriteString("\n")
+	})
+	return sb.String()
+}
+
+// String returns the operation's line within its execution plan.
+func (op *Operation) String() string {
+	return op.format(op.RecordsProduced > 0 || op.ExecutionTime > 0)
+}
+
+func (op *Operation) format(profiled bool) string {
+	parts := append([]string{op.Name}, op.Args...)
+	s := strings.Join(parts, " | ")
+	if profiled {
+		s += fmt.Sprintf(" | Records produced: %d, Execution time: %f ms", op.RecordsProduced, op.ExecutionTime)
+	}
+	return s
+}
+
+// planLines extracts the lines of an execution plan reply, older servers
+// reply with a single newline separated string.
+func planLines(reply interface{}) ([]string, error) {
+	if s, err := redis.String(reply, nil); err == nil {
+		return strings.Split(s, "\n"), nil
+	}
+	lines, err := redis.Strings(reply, nil)
+	if err != nil {
+		return nil, newParseError("malformed execution plan", reply, err)
+	}
+	return lines, nil
+}
+
+// plan issues cmd, either GRAPH.EXPLAIN or GRAPH.PROFILE, and returns the
+// lines of the reported execution plan.
+func (g *Graph) plan(ctx context.Context, cmd string, q string, params map[string]interface{}) ([]string, error) {
+	if params != nil {
+		header, err := EncodeParamsHeader(params)
+		if err != nil {
+			return nil, err
+		}
+		q = header + q
+	}
+
+	r, err := g.do(ctx, cmd, g.Id, q)
+	if err != nil {
+		return nil, err
+	}
+	return planLines(r)
+}
+
+// Explain returns the execution plan for given query without running it.
+func (g *Graph) Explain(q string, params map[string]interface{}) (*ExecutionPlan, error) {
+	return g.ExplainContext(context.Background(), q, params)
+}
+
+// ExplainContext returns the execution plan for given query without running it, honoring ctx.
+func (g *Graph) ExplainContext(ctx context.Context, q string, params map[string]interface{}) (*ExecutionPlan, error) {
+	lines, err := g.plan(ctx, "GRAPH.EXPLAIN", q, params)
+	if err != nil {
+		return nil, err
+	}
+	return ExecutionPlanNew(lines)
+}
+
+// Profile runs given query and returns its execution plan, annotated with the
+// number of records each operation produced and the time it took.
+func (g *Graph) Profile(q string, params map[string]interface{}) (*ExecutionPlan, error) {
+	return g.ProfileContext(context.Background(), q, params)
+}
+
+// ProfileContext runs given query and returns its annotated execution plan, honoring ctx.
+func (g *Graph) ProfileContext(ctx context.Context, q string, params map[string]interface{}) (*ExecutionPlan, error) {
+	lines, err := g.plan(ctx, "GRAPH.PROFILE", q, params)
+	if err != nil {
+		return nil, err
+	}
+	return ExecutionPlanNew(lines)
+}
@@ -0,0 +1,82 @@
+package redisgraph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Executor issues commands to a Redis server through any driver. Do returns
+// the command's raw reply, with error replies reported as redis.Error so that
+// they can be told apart from connection failures.
+//
+// Replies may use the representation of the driver, e.g. go-redis returns
+// bulk strings as string where redigo returns []byte, they are converted
+// before being parsed.
+type Executor interface {
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+}
+
+// ErrConnRequired is returned by operations which require a redigo
+// connection, such as transactions, when the graph issues commands through
+// an Executor.
+var ErrConnRequired = errors.New("redisgraph: operation requires a redigo connection, the graph uses an Executor")
+
+// GraphNewWithExecutor creates a new graph issuing every command through exec,
+// such a graph can be shared by multiple goroutines if exec can. Pipelined
+// queries are issued one at a time. Transactions require a redigo connection
+// and are not supported, Begin fails with ErrConnRequired, and Conn is nil.
+// The goredis package, a module of its own, provides an Executor for go-redis.
+func GraphNewWithExecutor(Id string, exec Executor) Graph {
+	return Graph{
+		Id:                Id,
+		Nodes:             make(map[string]*Node, 0),
+		Edges:             make([]*Edge, 0),
+		exec:              exec,
+		labels:            make([]string, 0),
+		relationshipTypes: make([]string, 0),
+		properties:        make([]string, 0),
+	}
+}
+
+// redigoExecutor issues commands over connections borrowed from a ConnProvider.
+type redigoExecutor struct {
+	pool ConnProvider
+}
+
+// RedigoExecutorNew returns an Executor borrowing a redigo connection from
+// pool for every command.
+func RedigoExecutorNew(pool ConnProvider) Executor {
+	return redigoExecutor{pool}
+}
+
+func (e redigoExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	conn, err := getProviderConn(ctx, e.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return doContext(ctx, conn, cmd, args...)
+}
+
+// normalizeReply converts a reply to the representation of redigo, in place:
+// strings become []byte, doubles, as sent over RESP3, their textual form and
+// errors redis.Error.
+func normalizeReply(r interface{}) interface{} {
+	switch v := r.(type) {
+	case string:
+		return []byte(v)
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'g', -1, 64))
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeReply(v[i])
+		}
+	case redis.Error:
+	case error:
+		return redis.Error(v.Error())
+	}
+	return r
+}
@@ -3,7 +3,8 @@ module github.com/RedisGraph/redisgraph-go
 go 1.12
 
 require (
-	github.com/gomodule/redigo v1.8.2
+	github.com/gomodule/redigo v1.8.9
 	github.com/olekukonko/tablewriter v0.0.4
-	github.com/stretchr/testify v1.6.1
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,25 @@
+module github.com/RedisGraph/redisgraph-go/goredis
+
+go 1.18
+
+require (
+	github.com/RedisGraph/redisgraph-go v0.0.0
+	github.com/alicebob/miniredis/v2 v2.33.0
+	github.com/gomodule/redigo v1.8.9
+	github.com/redis/go-redis/v9 v9.7.3
+	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/mattn/go-runewidth v0.0.7 // indirect
+	github.com/olekukonko/tablewriter v0.0.4 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+replace github.com/RedisGraph/redisgraph-go => ../
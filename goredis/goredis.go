@@ -0,0 +1,41 @@
+// Package goredis adapts go-redis clients to redisgraph, so that graphs can
+// share the connection pool of services using go-redis. It is a module of its
+// own, sparing users of redigo alone a dependency on go-redis.
+//
+// Graphs issuing commands through go-redis do not support transactions,
+// which require a redigo connection, see redisgraph.GraphNewWithExecutor.
+package goredis
+
+import (
+	"context"
+
+	redisgraph "github.com/RedisGraph/redisgraph-go"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// executor issues commands through a go-redis client.
+type executor struct {
+	client redis.UniversalClient
+}
+
+// ExecutorNew returns an Executor issuing commands through client, sharing
+// its connection pool.
+func ExecutorNew(client redis.UniversalClient) redisgraph.Executor {
+	return executor{client}
+}
+
+func (e executor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	cmdArgs := make([]interface{}, 0, len(args)+1)
+	cmdArgs = append(cmdArgs, cmd)
+	cmdArgs = append(cmdArgs, args...)
+
+	r, err := e.client.Do(ctx, cmdArgs...).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if _, ok := err.(redis.Error); ok {
+		return nil, redigo.Error(err.Error())
+	}
+	return r, err
+}
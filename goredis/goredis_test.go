@@ -0,0 +1,71 @@
+package goredis
+
+import (
+	"testing"
+
+	redisgraph "github.com/RedisGraph/redisgraph-go"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// graphQuery replies to graph queries as RedisGraph would, with a name and
+// a score, or fails queries returning an error.
+func graphQuery(c *server.Peer, cmd string, args []string) {
+	if len(args) < 2 {
+		c.WriteError("ERR wrong number of arguments for '" + cmd + "' command")
+		return
+	}
+	if args[1] == "RETURN error" {
+		c.WriteError("Invalid query")
+		return
+	}
+	c.WriteLen(3)
+	c.WriteLen(2)
+	for _, name := range []string{"name", "score"} {
+		c.WriteLen(2)
+		c.WriteInt(int(redisgraph.COLUMN_SCALAR))
+		c.WriteBulk(name)
+	}
+	c.WriteLen(1)
+	c.WriteLen(2)
+	c.WriteLen(2)
+	c.WriteInt(int(redisgraph.VALUE_STRING))
+	c.WriteBulk("John Doe")
+	c.WriteLen(2)
+	c.WriteInt(int(redisgraph.VALUE_DOUBLE))
+	c.WriteFloat(2.5)
+	c.WriteStrings([]string{"Cached execution: 0", "Query internal execution time: 0.1 milliseconds"})
+}
+
+func TestExecutor(t *testing.T) {
+	s := miniredis.RunT(t)
+	assert.Nil(t, s.Server().Register("GRAPH.QUERY", graphQuery))
+	assert.Nil(t, s.Server().Register("GRAPH.RO_QUERY", graphQuery))
+
+	for _, protocol := range []int{2, 3} {
+		client := redis.NewClient(&redis.Options{Addr: s.Addr(), Protocol: protocol})
+		defer client.Close()
+		g := redisgraph.GraphNewWithExecutor("social", ExecutorNew(client))
+
+		res, err := g.ROQuery("RETURN 1")
+		assert.Nil(t, err)
+		assert.True(t, res.Next())
+		assert.Equal(t, "John Doe", res.Record().GetByIndex(0))
+		assert.Equal(t, 2.5, res.Record().GetByIndex(1))
+		assert.Equal(t, 0.1, res.InternalExecutionTime())
+
+		_, err = g.Query("RETURN error")
+		assert.Equal(t, redigo.Error("Invalid query"), err)
+
+		results, err := g.Pipeline().Query("RETURN 1", nil, nil).ROQuery("RETURN error", nil, nil).Exec()
+		assert.Nil(t, err)
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, redigo.Error("Invalid query"), results[1].Err)
+
+		_, err = g.Begin()
+		assert.Equal(t, redisgraph.ErrConnRequired, err)
+	}
+}
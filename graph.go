@@ -1,16 +1,26 @@
 package redisgraph
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 )
 
 // QueryOptions are a set of additional arguments to be emitted with a query.
 type QueryOptions struct {
-	timeout           int
+	timeout        int
+	contextTimeout bool
+	lazy           bool
+}
+
+// ConnProvider hands out connections on demand, *redis.Pool satisfies it.
+// Connections obtained from a ConnProvider are closed once a command completes.
+type ConnProvider interface {
+	Get() redis.Conn
 }
 
 // Graph represents a graph, which is a collection of nodes and edges.
@@ -18,11 +28,37 @@ type Graph struct {
 	Id                string
 	Nodes             map[string]*Node
 	Edges             []*Edge
-	Conn              redis.Conn
-	labels            []string   // List of node labels.
-	relationshipTypes []string   // List of relation types.
-	properties        []string   // List of properties.
-	mutex             sync.Mutex // Lock, used for updating internal state.
+	Conn              redis.Conn   // Connection of graphs created by GraphNew, nil otherwise.
+	pool              ConnProvider // Connection source, nil when using Conn.
+	exec              Executor     // Command executor, set in place of Conn and pool.
+	connMutex         sync.Mutex   // Serializes access to Conn.
+	labels            []string     // List of node labels.
+	relationshipTypes []string     // List of relation types.
+	properties        []string     // List of properties.
+	mutex             sync.RWMutex // Lock, used for updating internal state.
+	removedNodes      []*Node      // Committed nodes to delete on the next commit.
+	removedEdges      []*Edge      // Committed edges to delete on the next commit.
+	integers          IntegerDecoding
+	replicas          *replicaSet   // Replicas serving read only queries, if any.
+	readYourWrites    time.Duration // Window read only queries stick to the primary after a write.
+	retry             *RetryPolicy  // Policy failed commands are retried under, nil to never retry.
+}
+
+// IntegerDecoding determines the Go type integers returned by queries decode to.
+type IntegerDecoding int
+
+const (
+	// INTEGER_INT decodes integers as int, integers overflowing int, as
+	// may happen on 32-bit platforms, are reported as a ParseError.
+	INTEGER_INT IntegerDecoding = iota
+	// INTEGER_INT64 decodes integers as int64, regardless of the platform.
+	INTEGER_INT64
+)
+
+// SetIntegerDecoding sets the Go type integers returned by queries decode to,
+// INTEGER_INT by default. It must not be called while the graph is in use.
+func (g *Graph) SetIntegerDecoding(decoding IntegerDecoding) {
+	g.integers = decoding
 }
 
 // New creates a new graph.
@@ -38,6 +74,107 @@ func GraphNew(Id string, conn redis.Conn) Graph {
 	}
 }
 
+// GraphNewWithPool creates a new graph which borrows a connection from pool
+// for every command it issues, such a graph can be shared by multiple goroutines.
+func GraphNewWithPool(Id string, pool ConnProvider) Graph {
+	return Graph{
+		Id:                Id,
+		Nodes:             make(map[string]*Node, 0),
+		Edges:             make([]*Edge, 0),
+		pool:              pool,
+		labels:            make([]string, 0),
+		relationshipTypes: make([]string, 0),
+		properties:        make([]string, 0),
+	}
+}
+
+// contextConnProvider is implemented by connection providers which are able
+// to honor a context while waiting for a connection, e.g. *redis.Pool.
+type contextConnProvider interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// getConn returns a connection to issue commands on, along with a function
+// which must be called once the connection is no longer needed.
+func (g *Graph) getConn(ctx context.Context) (redis.Conn, func(), error) {
+	if g.exec != nil {
+		return nil, nil, ErrConnRequired
+	}
+	if g.pool != nil {
+		conn, err := getProviderConn(ctx, g.pool)
+		if err != nil {
+			return nil, nil, err
+		}
+		return conn, func() { conn.Close() }, nil
+	}
+
+	g.connMutex.Lock()
+	return g.Conn, g.connMutex.Unlock, nil
+}
+
+// do issues a single command against the graph's connection, or one of its
+// replicas for read only queries, retrying it under the graph's retry policy.
+// Cancelling ctx while the command is in flight closes the connection.
+func (g *Graph) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.doRetry(ctx, cmd, args...)
+}
+
+// route issues a single command against the primary or, for read only
+// queries, one of the replicas.
+func (g *Graph) route(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if g.replicas != nil {
+		if cmd == "GRAPH.RO_QUERY" && !g.pinned() {
+			return g.doReplica(ctx, cmd, args...)
+		}
+		if isWriteCommand(cmd, args) {
+			r, err := g.doPrimary(ctx, cmd, args...)
+			if err == nil {
+				g.wrote()
+			}
+			return r, err
+		}
+	}
+	return g.doPrimary(ctx, cmd, args...)
+}
+
+// doPrimary issues a single command against the graph's connection.
+func (g *Graph) doPrimary(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if g.exec != nil {
+		r, err := g.exec.Do(ctx, cmd, args...)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeReply(r), nil
+	}
+
+	conn, release, err := g.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return doContext(ctx, conn, cmd, args...)
+}
+
+// getProviderConn borrows a connection from p, honoring ctx if p supports it.
+func getProviderConn(ctx context.Context, p ConnProvider) (redis.Conn, error) {
+	if cp, ok := p.(contextConnProvider); ok {
+		return cp.GetContext(ctx)
+	}
+	return p.Get(), nil
+}
+
+// doContext issues cmd over conn, honoring ctx if conn supports it.
+func doContext(ctx context.Context, conn redis.Conn, cmd string, args ...interface{}) (interface{}, error) {
+	if _, ok := conn.(redis.ConnWithContext); ok && ctx.Done() != nil {
+		return redis.DoContext(conn, ctx, cmd, args...)
+	}
+	return conn.Do(cmd, args...)
+}
+
 // AddNode adds a node to the graph.
 func (g *Graph) AddNode(n *Node) {
 	if n.Alias == "" {
@@ -49,42 +186,70 @@ func (g *Graph) AddNode(n *Node) {
 
 // AddEdge adds an edge to the graph.
 func (g *Graph) AddEdge(e *Edge) error {
-	// Verify that the edge has source and destination
-	if e.Source == nil || e.Destination == nil {
-		return fmt.Errorf("Both source and destination nodes should be defined")
-	}
+	// Edges retrieved by a query are matched by their ID and need not carry
+	// their endpoints.
+	if !e.persisted {
+		// Verify that the edge has source and destination
+		if e.Source == nil || e.Destination == nil {
+			return fmt.Errorf("Both source and destination nodes should be defined")
+		}
 
-	// Verify that the edge's nodes have been previously added to the graph
-	if _, ok := g.Nodes[e.Source.Alias]; !ok {
-		return fmt.Errorf("Source node neeeds to be added to the graph first")
-	}
-	if _, ok := g.Nodes[e.Destination.Alias]; !ok {
-		return fmt.Errorf("Destination node neeeds to be added to the graph first")
+		// Verify that the edge's nodes have been previously added to the graph
+		if _, ok := g.Nodes[e.Source.Alias]; !ok {
+			return fmt.Errorf("Source node neeeds to be added to the graph first")
+		}
+		if _, ok := g.Nodes[e.Destination.Alias]; !ok {
+			return fmt.Errorf("Destination node neeeds to be added to the graph first")
+		}
 	}
 
+	if e.alias == "" {
+		e.alias = RandomString(10)
+	}
 	e.graph = g
 	g.Edges = append(g.Edges, e)
 	return nil
 }
 
-// ExecutionPlan gets the execution plan for given query.
+// ExecutionPlan gets the execution plan for given query, see Explain for a
+// structured representation.
 func (g *Graph) ExecutionPlan(q string) (string, error) {
-	return redis.String(g.Conn.Do("GRAPH.EXPLAIN", g.Id, q))
+	return g.ExecutionPlanContext(context.Background(), q)
+}
+
+// ExecutionPlanContext gets the execution plan for given query, honoring ctx.
+func (g *Graph) ExecutionPlanContext(ctx context.Context, q string) (string, error) {
+	lines, err := g.plan(ctx, "GRAPH.EXPLAIN", q, nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
 }
 
 // Delete removes the graph.
 func (g *Graph) Delete() error {
-	_, err := g.Conn.Do("GRAPH.DELETE", g.Id)
+	return g.DeleteContext(context.Background())
+}
+
+// DeleteContext removes the graph, honoring ctx.
+func (g *Graph) DeleteContext(ctx context.Context) error {
+	_, err := g.do(ctx, "GRAPH.DELETE", g.Id)
 
 	// clear internal mappings
+	g.mutex.Lock()
 	g.labels = g.labels[:0]
 	g.properties = g.properties[:0]
 	g.relationshipTypes = g.relationshipTypes[:0]
+	g.mutex.Unlock()
 
+	if err == nil {
+		// Entities held by the graph no longer exist on the server.
+		g.forgetCommitted()
+	}
 	return err
 }
 
-// Flush will create the graph and clear it
+// Flush commits pending changes, see Commit, and clears the graph's local state.
 func (g *Graph) Flush() (*QueryResult, error) {
 	res, err := g.Commit()
 	if err == nil {
@@ -94,19 +259,6 @@ func (g *Graph) Flush() (*QueryResult, error) {
 	return res, err
 }
 
-// Commit creates the entire graph, but will re-add nodes if called again.
-func (g *Graph) Commit() (*QueryResult, error) {
-	items := make([]string, 0, len(g.Nodes)+len(g.Edges))
-	for _, n := range g.Nodes {
-		items = append(items, n.Encode())
-	}
-	for _, e := range g.Edges {
-		items = append(items, e.Encode())
-	}
-	q := "CREATE " + strings.Join(items, ",")
-	return g.Query(q)
-}
-
 // NewQueryOptions instantiates a new QueryOptions struct.
 func NewQueryOptions() *QueryOptions {
 	return &QueryOptions{
@@ -125,72 +277,128 @@ func (options *QueryOptions) GetTimeout() int {
 	return options.timeout
 }
 
-// Query executes a query against the graph.
-func (g *Graph) Query(q string) (*QueryResult, error) {
-	r, err := g.Conn.Do("GRAPH.QUERY", g.Id, q, "--compact")
-	if err != nil {
-		return nil, err
-	}
+// SetTimeoutFromContext makes queries derive their server-side timeout from
+// the deadline of the context they are issued with, when one is set and it
+// expires before the explicit timeout.
+func (options *QueryOptions) SetTimeoutFromContext(enabled bool) *QueryOptions {
+	options.contextTimeout = enabled
+	return options
+}
 
-	return QueryResultNew(g, r)
+// GetTimeoutFromContext reports whether the server-side timeout is derived from the context deadline.
+func (options *QueryOptions) GetTimeoutFromContext() bool {
+	return options.contextTimeout
 }
 
-// ROQuery executes a read only query against the graph.
-func (g *Graph) ROQuery(q string) (*QueryResult, error) {
+// SetLazy makes query results decode their records one at a time as they
+// are iterated over with Next, rather than all at once. Raw records are
+// released once decoded, reducing the memory held by large results. Labels,
+// relationship types and property keys the records reference are looked up
+// under the query's context, which must remain valid while iterating.
+func (options *QueryOptions) SetLazy(lazy bool) *QueryOptions {
+	options.lazy = lazy
+	return options
+}
 
-	r, err := g.Conn.Do("GRAPH.RO_QUERY", g.Id, q, "--compact")
-	if err != nil {
-		return nil, err
+// GetLazy reports whether query results decode their records lazily.
+func (options *QueryOptions) GetLazy() bool {
+	return options.lazy
+}
+
+// effectiveTimeout returns the timeout in milliseconds to send along with a
+// query issued under ctx, or -1 if no timeout should be sent.
+func (options *QueryOptions) effectiveTimeout(ctx context.Context) int {
+	if options == nil {
+		return -1
 	}
 
-	return QueryResultNew(g, r)
+	timeout := options.timeout
+	if options.contextTimeout {
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining := int(time.Until(deadline) / time.Millisecond)
+			if remaining < 1 {
+				remaining = 1
+			}
+			if timeout < 0 || remaining < timeout {
+				timeout = remaining
+			}
+		}
+	}
+	return timeout
 }
 
-func (g *Graph) ParameterizedQuery(q string, params map[string]interface{}) (*QueryResult, error) {
-	if(params != nil){
-		q = BuildParamsHeader(params) + q
+// queryArgs builds the arguments of a GRAPH.QUERY or GRAPH.RO_QUERY command.
+func (g *Graph) queryArgs(ctx context.Context, q string, params map[string]interface{}, options *QueryOptions) ([]interface{}, error) {
+	if params != nil {
+		header, err := EncodeParamsHeader(params)
+		if err != nil {
+			return nil, err
+		}
+		q = header + q
+	}
+
+	args := []interface{}{g.Id, q, "--compact"}
+	if timeout := options.effectiveTimeout(ctx); timeout >= 0 {
+		args = append(args, "timeout", timeout)
 	}
-	return g.Query(q);
+	return args, nil
 }
 
-// QueryWithOptions issues a query with the given timeout
-func (g *Graph) QueryWithOptions(q string, options *QueryOptions) (*QueryResult, error) {
-	var r interface{}
-	var err error
-	if(options.timeout >= 0) {
-		r, err = g.Conn.Do("GRAPH.QUERY", g.Id, q, "--compact", "timeout", options.timeout)
-	} else {
-		r, err = g.Conn.Do("GRAPH.QUERY", g.Id, q, "--compact")
+// query issues cmd, either GRAPH.QUERY or GRAPH.RO_QUERY, and parses its reply.
+func (g *Graph) query(ctx context.Context, cmd string, q string, params map[string]interface{}, options *QueryOptions) (*QueryResult, error) {
+	args, err := g.queryArgs(ctx, q, params, options)
+	if err != nil {
+		return nil, err
 	}
+
+	r, err := g.do(ctx, cmd, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return QueryResultNew(g, r)
+	return queryResultNew(ctx, g, r, options)
+}
+
+// Query executes a query against the graph.
+func (g *Graph) Query(q string) (*QueryResult, error) {
+	return g.QueryContext(context.Background(), q, nil, nil)
+}
+
+// QueryContext executes a query against the graph, honoring ctx.
+// Both params and options may be nil.
+func (g *Graph) QueryContext(ctx context.Context, q string, params map[string]interface{}, options *QueryOptions) (*QueryResult, error) {
+	return g.query(ctx, "GRAPH.QUERY", q, params, options)
+}
+
+// ROQuery executes a read only query against the graph.
+func (g *Graph) ROQuery(q string) (*QueryResult, error) {
+	return g.ROQueryContext(context.Background(), q, nil, nil)
+}
+
+// ROQueryContext executes a read only query against the graph, honoring ctx.
+// Both params and options may be nil.
+func (g *Graph) ROQueryContext(ctx context.Context, q string, params map[string]interface{}, options *QueryOptions) (*QueryResult, error) {
+	return g.query(ctx, "GRAPH.RO_QUERY", q, params, options)
+}
+
+// ParameterizedQuery executes a query with the given parameters.
+func (g *Graph) ParameterizedQuery(q string, params map[string]interface{}) (*QueryResult, error) {
+	return g.QueryContext(context.Background(), q, params, nil)
+}
+
+// QueryWithOptions issues a query with the given timeout
+func (g *Graph) QueryWithOptions(q string, options *QueryOptions) (*QueryResult, error) {
+	return g.QueryContext(context.Background(), q, nil, options)
 }
 
 // ParameterizedQueryWithOptions issues a parameterized query with the given timeout
 func (g *Graph) ParameterizedQueryWithOptions(q string, params map[string]interface{}, options *QueryOptions) (*QueryResult, error) {
-	if(params != nil){
-		q = BuildParamsHeader(params) + q
-	}
-	return g.QueryWithOptions(q, options);
+	return g.QueryContext(context.Background(), q, params, options)
 }
 
 // ROQueryWithOptions issues a read-only query with the given timeout
 func (g *Graph) ROQueryWithOptions(q string, options *QueryOptions) (*QueryResult, error) {
-	var r interface{}
-	var err error
-	if(options.timeout >= 0) {
-		r, err = g.Conn.Do("GRAPH.RO_QUERY", g.Id, q, "--compact", "timeout", options.timeout)
-	} else {
-		r, err = g.Conn.Do("GRAPH.RO_QUERY", g.Id, q, "--compact")
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return QueryResultNew(g, r)
+	return g.ROQueryContext(context.Background(), q, nil, options)
 }
 
 // Merge pattern
@@ -199,76 +407,94 @@ func (g *Graph) Merge(p string) (*QueryResult, error) {
 	return g.Query(q)
 }
 
-func (g *Graph) getLabel(lblIdx int) string {
-	if lblIdx >= len(g.labels) {
-		// Missing label, refresh label mapping table.
-		g.mutex.Lock()
-
-		// Recheck now that we've got the lock.
-		if lblIdx >= len(g.labels) {
-			g.labels = g.Labels()
-			// Retry.
-			if lblIdx >= len(g.labels) {
-				// Error!
-				panic("Unknown label index.")
-			}
-		}
-		g.mutex.Unlock()
+// lookup resolves idx against the cached mapping table, refreshing the
+// table via fetch when idx is not yet known.
+func (g *Graph) lookup(ctx context.Context, options *QueryOptions, table *[]string, idx int, fetch func(context.Context, *QueryOptions) ([]string, error)) (string, bool, error) {
+	g.mutex.RLock()
+	if idx >= 0 && idx < len(*table) {
+		v := (*table)[idx]
+		g.mutex.RUnlock()
+		return v, true, nil
 	}
+	g.mutex.RUnlock()
 
-	return g.labels[lblIdx]
-}
+	if idx < 0 {
+		return "", false, nil
+	}
 
-func (g *Graph) getRelation(relIdx int) string {
-	if relIdx >= len(g.relationshipTypes) {
-		// Missing relation type, refresh relation type mapping table.
-		g.mutex.Lock()
+	// Missing entry, refresh mapping table.
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 
-		// Recheck now that we've got the lock.
-		if relIdx >= len(g.relationshipTypes) {
-			g.relationshipTypes = g.RelationshipTypes()
-			// Retry.
-			if relIdx >= len(g.relationshipTypes) {
-				// Error!
-				panic("Unknown relation type index.")
-			}
+	// Recheck now that we've got the lock.
+	if idx >= len(*table) {
+		t, err := fetch(ctx, options)
+		if err != nil {
+			return "", false, err
+		}
+		*table = t
+		// Retry.
+		if idx >= len(*table) {
+			return "", false, nil
 		}
-		g.mutex.Unlock()
 	}
 
-	return g.relationshipTypes[relIdx]
+	return (*table)[idx], true, nil
 }
 
-func (g *Graph) getProperty(propIdx int) string {
-	if propIdx >= len(g.properties) {
-		// Missing property, refresh property mapping table.
-		g.mutex.Lock()
-
-		// Recheck now that we've got the lock.
-		if propIdx >= len(g.properties) {
-			g.properties = g.PropertyKeys()
+// getLabel, getRelation and getProperty resolve schema indices found within
+// reply, which unknown indices are reported along with.
+func (g *Graph) getLabel(ctx context.Context, options *QueryOptions, lblIdx int, reply interface{}) (string, error) {
+	l, ok, err := g.lookup(ctx, options, &g.labels, lblIdx, g.fetchLabels)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", newParseError(fmt.Sprintf("unknown label index %d", lblIdx), reply, nil)
+	}
+	return l, nil
+}
 
-			// Retry.
-			if propIdx >= len(g.properties) {
-				// Error!
-				panic("Unknown property index.")
-			}
-		}
-		g.mutex.Unlock()
+func (g *Graph) getRelation(ctx context.Context, options *QueryOptions, relIdx int, reply interface{}) (string, error) {
+	r, ok, err := g.lookup(ctx, options, &g.relationshipTypes, relIdx, g.fetchRelationshipTypes)
+	if err != nil {
+		return "", err
 	}
+	if !ok {
+		return "", newParseError(fmt.Sprintf("unknown relation type index %d", relIdx), reply, nil)
+	}
+	return r, nil
+}
 
-	return g.properties[propIdx]
+func (g *Graph) getProperty(ctx context.Context, options *QueryOptions, propIdx int, reply interface{}) (string, error) {
+	p, ok, err := g.lookup(ctx, options, &g.properties, propIdx, g.fetchPropertyKeys)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", newParseError(fmt.Sprintf("unknown property index %d", propIdx), reply, nil)
+	}
+	return p, nil
 }
 
 // Procedures
 
 // CallProcedure invokes procedure.
 func (g *Graph) CallProcedure(procedure string, yield []string, args ...interface{}) (*QueryResult, error) {
+	return g.CallProcedureContext(context.Background(), procedure, yield, args...)
+}
+
+// CallProcedureContext invokes procedure, honoring ctx.
+func (g *Graph) CallProcedureContext(ctx context.Context, procedure string, yield []string, args ...interface{}) (*QueryResult, error) {
 	q := fmt.Sprintf("CALL %s(", procedure)
 
 	tmp := make([]string, 0, len(args))
-	for arg := range args {
-		tmp = append(tmp, ToString(arg))
+	for _, arg := range args {
+		s, err := EncodeValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		tmp = append(tmp, s)
 	}
 	q += fmt.Sprintf("%s)", strings.Join(tmp, ","))
 
@@ -276,41 +502,71 @@ func (g *Graph) CallProcedure(procedure string, yield []string, args ...interfac
 		q += fmt.Sprintf(" YIELD %s", strings.Join(yield, ","))
 	}
 
-	return g.Query(q)
+	return g.QueryContext(ctx, q, nil, nil)
 }
 
-// Labels, retrieves all node labels.
-func (g *Graph) Labels() []string {
-	qr, _ := g.CallProcedure("db.labels", nil)
+// procedureStrings calls procedure and collects the first column of its result.
+// The call is read only and issued against the primary, which knows of every
+// entry a result being parsed may reference, even one served by a replica
+// lagging behind.
+func (g *Graph) procedureStrings(ctx context.Context, options *QueryOptions, procedure string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	args, err := g.queryArgs(ctx, fmt.Sprintf("CALL %s()", procedure), nil, options)
+	if err != nil {
+		return nil, err
+	}
+	r, err := g.doPrimary(ctx, "GRAPH.RO_QUERY", args...)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := queryResultNew(ctx, g, r, options)
+	if err != nil {
+		return nil, err
+	}
 
 	l := make([]string, len(qr.results))
 
 	for idx, r := range qr.results {
-		l[idx] = r.GetByIndex(0).(string)
+		s, ok := r.GetByIndex(0).(string)
+		if !ok {
+			return nil, newParseError(fmt.Sprintf("%s returned a non-string value", procedure), r.GetByIndex(0), nil)
+		}
+		l[idx] = s
 	}
+	return l, nil
+}
+
+func (g *Graph) fetchLabels(ctx context.Context, options *QueryOptions) ([]string, error) {
+	return g.procedureStrings(ctx, options, "db.labels")
+}
+
+func (g *Graph) fetchRelationshipTypes(ctx context.Context, options *QueryOptions) ([]string, error) {
+	return g.procedureStrings(ctx, options, "db.relationshipTypes")
+}
+
+func (g *Graph) fetchPropertyKeys(ctx context.Context, options *QueryOptions) ([]string, error) {
+	return g.procedureStrings(ctx, options, "db.propertyKeys")
+}
+
+// Labels, retrieves all node labels.
+// An empty list is returned if the labels could not be retrieved.
+func (g *Graph) Labels() []string {
+	l, _ := g.fetchLabels(context.Background(), nil)
 	return l
 }
 
 // RelationshipTypes, retrieves all edge relationship types.
+// An empty list is returned if the relationship types could not be retrieved.
 func (g *Graph) RelationshipTypes() []string {
-	qr, _ := g.CallProcedure("db.relationshipTypes", nil)
-
-	rt := make([]string, len(qr.results))
-
-	for idx, r := range qr.results {
-		rt[idx] = r.GetByIndex(0).(string)
-	}
+	rt, _ := g.fetchRelationshipTypes(context.Background(), nil)
 	return rt
 }
 
 // PropertyKeys, retrieves all properties names.
+// An empty list is returned if the property keys could not be retrieved.
 func (g *Graph) PropertyKeys() []string {
-	qr, _ := g.CallProcedure("db.propertyKeys", nil)
-
-	p := make([]string, len(qr.results))
-
-	for idx, r := range qr.results {
-		p[idx] = r.GetByIndex(0).(string)
-	}
+	p, _ := g.fetchPropertyKeys(context.Background(), nil)
 	return p
 }
@@ -0,0 +1,521 @@
+package redisgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Results encode to JSON as follows, decoding them back yields identical
+// values:
+//
+//	node:     {"$type": "node", "id": 1, "labels": ["Person"], "properties": {...}}
+//	edge:     {"$type": "edge", "id": 2, "type": "VISITED", "src": 1, "dst": 3, "properties": {...}}
+//	path:     {"$type": "path", "nodes": [...], "edges": [...]}
+//	point:    {"$type": "point", "latitude": 32.5, "longitude": -117.0}
+//	vector:   {"$type": "vecf32", "value": [1.0, 2.5]}
+//	time:     {"$type": "datetime", "value": "2020-01-01T00:00:00Z"}
+//	duration: {"$type": "duration", "value": 3600000000000}
+//	record:   {"keys": ["p", ...], "values": [...]}
+//	result:   {"header": ["p", ...], "records": [[...], ...], "statistics": {...}, "integers": "int64"}
+//
+// Floats always carry a fraction or exponent, telling them apart from
+// integers, which decode as int. Results of graphs decoding integers as
+// int64, see Graph.SetIntegerDecoding, carry "integers": "int64" so that
+// their integers decode as int64 again. Maps encode as objects, unless they hold a
+// "$type" key themselves, in which case they are wrapped as
+// {"$type": "map", "value": {...}}, so that objects are decoded by their
+// "$type" tag alone, never by their shape.
+
+// jsonTagged is a value encoded along with its type.
+type jsonTagged struct {
+	Type  string      `json:"$type"`
+	Value interface{} `json:"value"`
+}
+
+type jsonPoint struct {
+	Type      string  `json:"$type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type jsonNode struct {
+	Type       string                 `json:"$type"`
+	ID         uint64                 `json:"id"`
+	Labels     []string               `json:"labels"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type jsonEdge struct {
+	Type        string                 `json:"$type"`
+	ID          uint64                 `json:"id"`
+	Relation    string                 `json:"type"`
+	Source      uint64                 `json:"src"`
+	Destination uint64                 `json:"dst"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+type jsonPath struct {
+	Type  string  `json:"$type"`
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+type jsonRecord struct {
+	Keys   []string      `json:"keys"`
+	Values []interface{} `json:"values"`
+}
+
+type jsonQueryResult struct {
+	Header     []string           `json:"header"`
+	Records    [][]interface{}    `json:"records"`
+	Statistics map[string]float64 `json:"statistics"`
+	Integers   string             `json:"integers,omitempty"`
+}
+
+// MarshalJSON encodes the node as {"$type", "id", "labels", "properties"}.
+func (n Node) MarshalJSON() ([]byte, error) {
+	labels := n.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return json.Marshal(jsonNode{"node", n.ID, labels, jsonProperties(n.Properties)})
+}
+
+// UnmarshalJSON decodes a node encoded by MarshalJSON.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	var v struct {
+		ID         uint64          `json:"id"`
+		Labels     []string        `json:"labels"`
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	props, err := unmarshalProperties(v.Properties)
+	if err != nil {
+		return err
+	}
+	*n = *NodeNew(v.Labels, "", props)
+	n.ID = v.ID
+	n.markCommitted()
+	return nil
+}
+
+// MarshalJSON encodes the edge as {"$type", "id", "type", "src", "dst",
+// "properties"}.
+func (e Edge) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEdge{"edge", e.ID, e.Relation, e.SourceNodeID(), e.DestNodeID(), jsonProperties(e.Properties)})
+}
+
+// UnmarshalJSON decodes an edge encoded by MarshalJSON, the edge's Source and
+// Destination are left nil.
+func (e *Edge) UnmarshalJSON(data []byte) error {
+	var v struct {
+		ID          uint64          `json:"id"`
+		Relation    string          `json:"type"`
+		Source      uint64          `json:"src"`
+		Destination uint64          `json:"dst"`
+		Properties  json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	props, err := unmarshalProperties(v.Properties)
+	if err != nil {
+		return err
+	}
+	*e = *EdgeNew(v.Relation, nil, nil, props)
+	e.ID = v.ID
+	e.srcNodeID = v.Source
+	e.destNodeID = v.Destination
+	e.markCommitted()
+	return nil
+}
+
+// MarshalJSON encodes the path as {"$type", "nodes", "edges"}.
+func (p Path) MarshalJSON() ([]byte, error) {
+	v := jsonPath{"path", p.Nodes, p.Edges}
+	if v.Nodes == nil {
+		v.Nodes = []*Node{}
+	}
+	if v.Edges == nil {
+		v.Edges = []*Edge{}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a path encoded by MarshalJSON.
+func (p *Path) UnmarshalJSON(data []byte) error {
+	var v jsonPath
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.Nodes, p.Edges = v.Nodes, v.Edges
+	return nil
+}
+
+// MarshalJSON encodes the record as {"keys", "values"}.
+func (r Record) MarshalJSON() ([]byte, error) {
+	keys := r.keys
+	if keys == nil {
+		keys = []string{}
+	}
+	return json.Marshal(jsonRecord{keys, jsonValues(r.values)})
+}
+
+// UnmarshalJSON decodes a record encoded by MarshalJSON.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Keys   []string        `json:"keys"`
+		Values json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	values, err := unmarshalValues(v.Values)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(v.Keys) {
+		return fmt.Errorf("redisgraph: record has %d keys but %d values", len(v.Keys), len(values))
+	}
+	*r = *recordNew(values, v.Keys, columnIndex(v.Keys))
+	return nil
+}
+
+// MarshalJSON encodes the result as {"header", "records", "statistics"}.
+// Lazy results have their remaining records consumed.
+func (qr *QueryResult) MarshalJSON() ([]byte, error) {
+	v := jsonQueryResult{
+		Header:     qr.header.column_names,
+		Records:    [][]interface{}{},
+		Statistics: qr.statistics,
+	}
+	if v.Header == nil {
+		v.Header = []string{}
+	}
+	if v.Statistics == nil {
+		v.Statistics = map[string]float64{}
+	}
+	if qr.integerDecoding() == INTEGER_INT64 {
+		v.Integers = "int64"
+	}
+	err := qr.forEachRecord(func(r *Record) error {
+		v.Records = append(v.Records, jsonValues(r.values))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a result encoded by MarshalJSON, which can be
+// iterated over as if it had just been returned by a query.
+func (qr *QueryResult) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Header     []string           `json:"header"`
+		Records    []json.RawMessage  `json:"records"`
+		Statistics map[string]float64 `json:"statistics"`
+		Integers   string             `json:"integers"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	res := newQueryResult(nil)
+	switch v.Integers {
+	case "":
+	case "int64":
+		res.integers = INTEGER_INT64
+	default:
+		return fmt.Errorf("redisgraph: unknown integer decoding %q", v.Integers)
+	}
+	for _, name := range v.Header {
+		res.header.column_names = append(res.header.column_names, name)
+		res.header.column_types = append(res.header.column_types, COLUMN_SCALAR)
+	}
+	res.header.column_index = columnIndex(res.header.column_names)
+	res.statistics = v.Statistics
+	if res.statistics == nil {
+		res.statistics = make(map[string]float64)
+	}
+
+	res.results = make([]*Record, len(v.Records))
+	for i, raw := range v.Records {
+		values, err := unmarshalValues(raw)
+		if err != nil {
+			return err
+		}
+		if len(values) != len(v.Header) {
+			return fmt.Errorf("redisgraph: record %d has %d values, header has %d columns", i, len(values), len(v.Header))
+		}
+		if res.integers == INTEGER_INT64 {
+			for j := range values {
+				values[j] = widenIntegers(values[j])
+			}
+		}
+		res.results[i] = recordNew(values, res.header.column_names, res.header.column_index)
+	}
+
+	*qr = *res
+	return nil
+}
+
+// widenIntegers converts the integers held by v, which JSON decodes as int,
+// to int64.
+func widenIntegers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = widenIntegers(v[i])
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = widenIntegers(e)
+		}
+	case *Node:
+		widenIntegers(v.Properties)
+	case *Edge:
+		widenIntegers(v.Properties)
+	case Path:
+		for _, n := range v.Nodes {
+			widenIntegers(n)
+		}
+		for _, e := range v.Edges {
+			widenIntegers(e)
+		}
+	}
+	return v
+}
+
+// jsonValue prepares v for encoding, floats are encoded by formatFloat so
+// that they are not mistaken for integers when decoded, and values JSON has
+// no type for are tagged with theirs.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case float64:
+		return jsonFloat(v, 64)
+	case float32:
+		return jsonFloat(float64(v), 32)
+	case []interface{}:
+		return jsonValues(v)
+	case map[string]interface{}:
+		if _, ok := v["$type"]; ok {
+			return jsonTagged{"map", jsonProperties(v)}
+		}
+		return jsonProperties(v)
+	case Point:
+		return jsonPoint{"point", v.Latitude, v.Longitude}
+	case Vector32:
+		elems := make([]interface{}, len(v))
+		for i, f := range v {
+			elems[i] = jsonFloat(float64(f), 32)
+		}
+		return jsonTagged{"vecf32", elems}
+	case time.Time:
+		return jsonTagged{"datetime", v.Format(time.RFC3339Nano)}
+	case time.Duration:
+		return jsonTagged{"duration", int64(v)}
+	}
+	return v
+}
+
+// jsonFloat encodes f, leaving values with no JSON representation, such as
+// NaN, for encoding/json to report.
+func jsonFloat(f float64, bits int) interface{} {
+	s, err := formatFloat(f, bits)
+	if err != nil {
+		return f
+	}
+	return json.RawMessage(s)
+}
+
+func jsonValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	c := make([]interface{}, len(values))
+	for i, v := range values {
+		c[i] = jsonValue(v)
+	}
+	return c
+}
+
+func jsonProperties(props map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		c[k] = jsonValue(v)
+	}
+	return c
+}
+
+// unmarshalValues decodes a JSON list of values, see decodeJSONValue.
+func unmarshalValues(data []byte) ([]interface{}, error) {
+	v, err := unmarshalValue(data)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := v.([]interface{})
+	if !ok && v != nil {
+		return nil, fmt.Errorf("redisgraph: expected a JSON list, got %s", data)
+	}
+	return values, nil
+}
+
+// unmarshalProperties decodes a JSON object of values, see decodeJSONValue.
+func unmarshalProperties(data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var raw map[string]interface{}
+	if err := d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	props := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		p, err := decodeJSONValue(v)
+		if err != nil {
+			return nil, err
+		}
+		props[k] = p
+	}
+	return props, nil
+}
+
+func unmarshalValue(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var raw interface{}
+	if err := d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(raw)
+}
+
+// decodeJSONValue converts a value decoded by encoding/json, with UseNumber,
+// to the types query results hold: integers become int, other numbers
+// float64, and objects tagged with a "$type" the values they encode.
+func decodeJSONValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		s := string(v)
+		if !strings.ContainsAny(s, ".eE") {
+			if i, err := strconv.Atoi(s); err == nil {
+				return i, nil
+			}
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		return v.Float64()
+
+	case []interface{}:
+		for i := range v {
+			e, err := decodeJSONValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = e
+		}
+		return v, nil
+
+	case map[string]interface{}:
+		if _, ok := v["$type"]; ok {
+			return decodeJSONTagged(v)
+		}
+		return decodeJSONMap(v)
+	}
+	return v, nil
+}
+
+func decodeJSONMap(m map[string]interface{}) (map[string]interface{}, error) {
+	for k, e := range m {
+		d, err := decodeJSONValue(e)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = d
+	}
+	return m, nil
+}
+
+// decodeJSONTagged decodes an object tagged with its "$type".
+func decodeJSONTagged(v map[string]interface{}) (interface{}, error) {
+	typ, _ := v["$type"].(string)
+	value := v["value"]
+	switch typ {
+	case "node", "edge", "path", "point":
+		// Decode the object again, into its type.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var target interface{}
+		switch typ {
+		case "node":
+			target = &Node{}
+		case "edge":
+			target = &Edge{}
+		case "path":
+			target = &Path{}
+		default:
+			target = &jsonPoint{}
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+		switch t := target.(type) {
+		case *Path:
+			return *t, nil
+		case *jsonPoint:
+			return Point{Latitude: t.Latitude, Longitude: t.Longitude}, nil
+		}
+		return target, nil
+
+	case "map":
+		if m, ok := value.(map[string]interface{}); ok {
+			return decodeJSONMap(m)
+		}
+
+	case "vecf32":
+		if elems, ok := value.([]interface{}); ok {
+			vec := make(Vector32, len(elems))
+			for i, e := range elems {
+				n, ok := e.(json.Number)
+				if !ok {
+					return nil, fmt.Errorf("redisgraph: malformed JSON vector element %v", e)
+				}
+				f, err := strconv.ParseFloat(string(n), 32)
+				if err != nil {
+					return nil, err
+				}
+				vec[i] = float32(f)
+			}
+			return vec, nil
+		}
+
+	case "datetime":
+		if s, ok := value.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+
+	case "duration":
+		if n, ok := value.(json.Number); ok {
+			d, err := n.Int64()
+			return time.Duration(d), err
+		}
+
+	default:
+		return nil, fmt.Errorf("redisgraph: unknown JSON value type %v", v["$type"])
+	}
+	return nil, fmt.Errorf("redisgraph: malformed JSON %s value %v", typ, value)
+}
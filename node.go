@@ -7,11 +7,14 @@ import (
 
 // Node represents a node within a graph
 type Node struct {
-	ID         uint64
-	Labels     []string
-	Alias      string
-	Properties map[string]interface{}
-	graph      *Graph
+	ID              uint64
+	Labels          []string
+	Alias           string
+	Properties      map[string]interface{}
+	graph           *Graph
+	persisted       bool                   // Set once the node exists on the server.
+	committed       map[string]interface{} // Properties as of the last commit.
+	committedLabels []string               // Labels as of the last commit.
 }
 
 // NodeNew create a new Node
@@ -47,17 +50,25 @@ func (n Node) String() string {
 		return "{}"
 	}
 
-	p := make([]string, 0, len(n.Properties))
-	for k, v := range n.Properties {
-		p = append(p, fmt.Sprintf("%s:%v", k, ToString(v)))
-	}
+	p, _ := formatProperties(n.Properties, encodeBestEffort)
+	return fmt.Sprintf("{%s}", p)
+}
 
-	s := fmt.Sprintf("{%s}", strings.Join(p, ","))
+// Encode returns the node as a Cypher pattern.
+//
+// Deprecated: properties which can not be encoded are formatted by
+// fmt.Sprint instead, yielding invalid Cypher, use EncodePattern.
+func (n Node) Encode() string {
+	s, _ := n.encode(encodeBestEffort)
 	return s
 }
 
-// Encode makes Node satisfy the Stringer interface
-func (n Node) Encode() string {
+// EncodePattern returns the node as a Cypher pattern, e.g. (alias:Label {k:v}).
+func (n Node) EncodePattern() (string, error) {
+	return n.encode(EncodeValue)
+}
+
+func (n Node) encode(encode func(interface{}) (string, error)) (string, error) {
 	s := []string{"("}
 
 	if n.Alias != "" {
@@ -69,16 +80,13 @@ func (n Node) Encode() string {
 	}
 
 	if len(n.Properties) > 0 {
-		p := make([]string, 0, len(n.Properties))
-		for k, v := range n.Properties {
-			p = append(p, fmt.Sprintf("%s:%v", k, ToString(v)))
+		p, err := formatProperties(n.Properties, encode)
+		if err != nil {
+			return "", err
 		}
-
-		s = append(s, "{")
-		s = append(s, strings.Join(p, ","))
-		s = append(s, "}")
+		s = append(s, "{", p, "}")
 	}
 
 	s = append(s, ")")
-	return strings.Join(s, "")
+	return strings.Join(s, ""), nil
 }
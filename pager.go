@@ -0,0 +1,145 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pager iterates over the records of a query too large to be returned at
+// once, issuing the query once per page. Pages are read only queries and
+// decode their records lazily. A Pager is not safe for concurrent use.
+type Pager struct {
+	graph    *Graph
+	q        string
+	params   map[string]interface{}
+	options  *QueryOptions
+	pageSize int
+	cursor   string      // Cursor column and parameter, empty when paging with SKIP.
+	next     interface{} // Cursor value of the next page.
+	skip     int         // Records skipped by the next page.
+	page     *QueryResult
+	read     int // Records read from the current page.
+	done     bool
+	err      error
+}
+
+// Paginate returns an iterator over the records of q, fetched pageSize
+// records at a time by appending SKIP and LIMIT clauses to q. q should order
+// its records, e.g. by ID, for pages not to overlap.
+func (g *Graph) Paginate(q string, params map[string]interface{}, pageSize int) *Pager {
+	p := &Pager{
+		graph:    g,
+		q:        q,
+		params:   params,
+		options:  NewQueryOptions().SetLazy(true),
+		pageSize: pageSize,
+	}
+	if pageSize < 1 {
+		p.err = fmt.Errorf("redisgraph: invalid page size %d", pageSize)
+	}
+	return p
+}
+
+// WithCursor pages by cursor rather than SKIP, which spares the server from
+// producing skipped records over and over. q must filter and order its
+// records by the parameter named cursor and return each record's cursor
+// under a column of the same name, e.g.
+//
+//	MATCH (n:Person) WHERE id(n) > $cursor RETURN n, id(n) AS cursor ORDER BY cursor
+//
+// The first page is queried with the cursor set to start, each subsequent
+// page with the cursor of the last record of the previous page.
+func (p *Pager) WithCursor(cursor string, start interface{}) *Pager {
+	p.cursor = cursor
+	p.next = start
+	return p
+}
+
+// WithOptions sets the options pages are queried with, records are always
+// decoded lazily.
+func (p *Pager) WithOptions(options *QueryOptions) *Pager {
+	o := *options
+	o.lazy = true
+	p.options = &o
+	return p
+}
+
+// Next advances to the next record, fetching the next page when the current
+// one is exhausted. Next returns false once all records have been read or an
+// error occurred, see Err.
+func (p *Pager) Next() bool {
+	return p.NextContext(context.Background())
+}
+
+// NextContext advances to the next record, honoring ctx should a page be fetched.
+func (p *Pager) NextContext(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	for {
+		if p.page != nil {
+			if p.page.Next() {
+				p.read++
+				if p.cursor != "" {
+					p.next, _ = p.page.Record().Get(p.cursor)
+				}
+				return true
+			}
+			if p.err = p.page.Err(); p.err != nil {
+				return false
+			}
+			// A short page is the last one.
+			if p.read < p.pageSize {
+				p.done = true
+			}
+			p.page = nil
+		}
+		if p.done {
+			return false
+		}
+		if p.page, p.err = p.fetch(ctx); p.err != nil {
+			return false
+		}
+		p.read = 0
+	}
+}
+
+// fetch queries the next page.
+func (p *Pager) fetch(ctx context.Context) (*QueryResult, error) {
+	params := make(map[string]interface{}, len(p.params)+1)
+	for k, v := range p.params {
+		params[k] = v
+	}
+
+	q := p.q
+	if p.cursor != "" {
+		params[p.cursor] = p.next
+		q += fmt.Sprintf(" LIMIT %d", p.pageSize)
+	} else {
+		q += fmt.Sprintf(" SKIP %d LIMIT %d", p.skip, p.pageSize)
+		p.skip += p.pageSize
+	}
+
+	res, err := p.graph.ROQueryContext(ctx, q, params, p.options)
+	if err != nil {
+		return nil, err
+	}
+	if p.cursor != "" && !res.Empty() && !containsString(res.header.column_names, p.cursor) {
+		return nil, fmt.Errorf("redisgraph: paged query does not return cursor column %q", p.cursor)
+	}
+	return res, nil
+}
+
+// Record returns the current record.
+func (p *Pager) Record() *Record {
+	if p.page == nil {
+		return nil
+	}
+	return p.page.Record()
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
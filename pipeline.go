@@ -0,0 +1,178 @@
+package redisgraph
+
+import (
+	"context"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Pipeline queues queries against a graph and issues them in a single round
+// trip, or one at a time for graphs created with GraphNewWithExecutor.
+// A Pipeline is not safe for concurrent use.
+type Pipeline struct {
+	graph   *Graph
+	queries []pipelineQuery
+}
+
+type pipelineQuery struct {
+	cmd     string
+	q       string
+	params  map[string]interface{}
+	options *QueryOptions
+}
+
+// PipelineResult is the outcome of a single pipelined query,
+// either Result or Err is set.
+type PipelineResult struct {
+	Result *QueryResult
+	Err    error
+}
+
+// Pipeline creates a new, empty, pipeline of queries against the graph.
+func (g *Graph) Pipeline() *Pipeline {
+	return &Pipeline{graph: g}
+}
+
+// Query queues a query, both params and options may be nil.
+func (p *Pipeline) Query(q string, params map[string]interface{}, options *QueryOptions) *Pipeline {
+	p.queries = append(p.queries, pipelineQuery{"GRAPH.QUERY", q, params, options})
+	return p
+}
+
+// ROQuery queues a read only query, both params and options may be nil.
+func (p *Pipeline) ROQuery(q string, params map[string]interface{}, options *QueryOptions) *Pipeline {
+	p.queries = append(p.queries, pipelineQuery{"GRAPH.RO_QUERY", q, params, options})
+	return p
+}
+
+// Len returns the number of queued queries.
+func (p *Pipeline) Len() int {
+	return len(p.queries)
+}
+
+// Exec issues all queued queries and returns their results, in the order the
+// queries were queued. A failing query does not prevent the others from being
+// executed, its error is reported by its own result. An error is returned
+// only when the queries could not be sent at all. The pipeline is emptied,
+// ready to be reused.
+func (p *Pipeline) Exec() ([]PipelineResult, error) {
+	return p.ExecContext(context.Background())
+}
+
+// ExecContext issues all queued queries, honoring ctx.
+func (p *Pipeline) ExecContext(ctx context.Context) ([]PipelineResult, error) {
+	queries := p.queries
+	p.queries = nil
+
+	results := make([]PipelineResult, len(queries))
+	if len(queries) == 0 {
+		return results, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	replies, err := p.roundTrip(ctx, queries, results)
+	if err != nil {
+		return nil, err
+	}
+	// Parsing may issue procedure calls of its own, hence it is deferred
+	// until the connection has been released.
+	for i, r := range replies {
+		if results[i].Err == nil {
+			results[i].Result, results[i].Err = queryResultNew(ctx, p.graph, r, queries[i].options)
+		}
+	}
+
+	// Only successful writes pin reads to the primary, a write may fail
+	// with an error found within its reply while parsing.
+	for i, pq := range queries {
+		if results[i].Err == nil && isWriteCommand(pq.cmd, nil) {
+			p.graph.wrote()
+			break
+		}
+	}
+	return results, nil
+}
+
+// roundTrip sends queries over a single connection and receives their raw
+// replies. Queries which could not be built or whose reply could not be
+// received have their error recorded within results.
+func (p *Pipeline) roundTrip(ctx context.Context, queries []pipelineQuery, results []PipelineResult) ([]interface{}, error) {
+	if p.graph.exec != nil {
+		return p.issue(ctx, queries, results), nil
+	}
+
+	conn, release, err := p.graph.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sent := 0
+	for i, pq := range queries {
+		args, err := p.graph.queryArgs(ctx, pq.q, pq.params, pq.options)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := conn.Send(pq.cmd, args...); err != nil {
+			return nil, err
+		}
+		sent++
+	}
+	if sent == 0 {
+		return make([]interface{}, len(queries)), nil
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+
+	_, withContext := conn.(redis.ConnWithContext)
+	withContext = withContext && ctx.Done() != nil
+
+	replies := make([]interface{}, len(queries))
+	var connErr error
+	for i := range queries {
+		if results[i].Err != nil {
+			continue
+		}
+		if connErr != nil {
+			// The connection is broken, remaining replies are lost.
+			results[i].Err = connErr
+			continue
+		}
+
+		var r interface{}
+		if withContext {
+			r, err = redis.ReceiveContext(conn, ctx)
+		} else {
+			r, err = conn.Receive()
+		}
+		if err != nil {
+			switch err.(type) {
+			case redis.Error, *ReadOnlyError:
+			default:
+				connErr = err
+			}
+			results[i].Err = err
+			continue
+		}
+		replies[i] = r
+	}
+	return replies, nil
+}
+
+// issue issues queries one at a time through the graph's Executor, which
+// offers no way of pipelining them.
+func (p *Pipeline) issue(ctx context.Context, queries []pipelineQuery, results []PipelineResult) []interface{} {
+	replies := make([]interface{}, len(queries))
+	for i, pq := range queries {
+		args, err := p.graph.queryArgs(ctx, pq.q, pq.params, pq.options)
+		if err == nil {
+			replies[i], err = p.graph.doPrimary(ctx, pq.cmd, args...)
+		}
+		results[i].Err = err
+	}
+	return replies
+}
@@ -1,13 +1,14 @@
 package redisgraph
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
-	"github.com/olekukonko/tablewriter"
 )
 
 const (
@@ -46,24 +47,40 @@ const (
 	VALUE_NODE
 	VALUE_PATH
 	VALUE_MAP
+	VALUE_POINT
+	VALUE_VECTORF32
+	VALUE_DATETIME
+	VALUE_DATE
+	VALUE_TIME
+	VALUE_DURATION
 )
 
 type QueryResultHeader struct {
 	column_names []string
 	column_types []ResultSetColumnTypes
+	column_index map[string]int // Column positions by name, shared by all records.
 }
 
 // QueryResult represents the results of a query.
 type QueryResult struct {
-	graph              *Graph
-	header             QueryResultHeader
-	results            []*Record
-	statistics         map[string]float64
-	currentRecordIdx   int
+	graph            *Graph
+	header           QueryResultHeader
+	results          []*Record
+	statistics       map[string]float64
+	currentRecordIdx int
+	lazy             bool            // Records are decoded by Next, one at a time.
+	pending          []interface{}   // Raw records yet to be decoded, lazy results only.
+	record           *Record         // Current record, lazy results only.
+	rows             int             // Number of records, lazy results only.
+	err              error           // Error met decoding a record, lazy results only.
+	ctx              context.Context // Context of the query, governing schema refreshes while parsing.
+	lookupOptions    *QueryOptions   // Options of schema refreshes while parsing.
+	integers         IntegerDecoding // Integer decoding of results decoded from JSON.
 }
 
-func QueryResultNew(g *Graph, response interface{}) (*QueryResult, error) {
-	qr := &QueryResult{
+// newQueryResult returns an empty result, holding no records nor statistics.
+func newQueryResult(g *Graph) *QueryResult {
+	return &QueryResult{
 		results:    nil,
 		statistics: nil,
 		header: QueryResultHeader{
@@ -73,8 +90,35 @@ func QueryResultNew(g *Graph, response interface{}) (*QueryResult, error) {
 		graph:              g,
 		currentRecordIdx: -1,
 	}
+}
+
+// QueryResultNew parses a reply to a graph query, as returned by redigo or by
+// any other driver, see Executor.
+func QueryResultNew(g *Graph, response interface{}) (*QueryResult, error) {
+	return queryResultNew(context.Background(), g, normalizeReply(response), nil)
+}
+
+// queryResultNew parses response to a query issued under ctx with options.
+// Lazy results have their records left encoded until iterated over by Next.
+// The labels, relationship types and property keys records reference are
+// looked up honoring ctx, for lazy results as well.
+func queryResultNew(ctx context.Context, g *Graph, response interface{}, options *QueryOptions) (*QueryResult, error) {
+	qr := newQueryResult(g)
+	qr.ctx = ctx
+	if options != nil {
+		qr.lazy = options.lazy
+		if options.contextTimeout {
+			qr.lookupOptions = NewQueryOptions().SetTimeoutFromContext(true)
+		}
+	}
 
-	r, _ := redis.Values(response, nil)
+	r, err := redis.Values(response, nil)
+	if err != nil {
+		return nil, newParseError("unexpected reply", response, err)
+	}
+	if len(r) == 0 {
+		return nil, newParseError("empty reply", response, nil)
+	}
 
 	// Check to see if we're encountered a run-time error.
 	if err, ok := r[len(r)-1].(redis.Error); ok {
@@ -82,203 +126,448 @@ func QueryResultNew(g *Graph, response interface{}) (*QueryResult, error) {
 	}
 
 	if len(r) == 1 {
-		qr.parseStatistics(r[0])
+		err = qr.parseStatistics(r[0])
+	} else if len(r) == 3 {
+		if err = qr.parseResults(r); err == nil {
+			err = qr.parseStatistics(r[2])
+		}
 	} else {
-		qr.parseResults(r)
-		qr.parseStatistics(r[2])
+		err = newParseError("unexpected number of reply elements", response, nil)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return qr, nil
 }
 
 func (qr *QueryResult) Empty() bool {
+	if qr.lazy {
+		return qr.rows == 0
+	}
 	return len(qr.results) == 0
 }
 
-func (qr *QueryResult) parseResults(raw_result_set []interface{}) {
+func (qr *QueryResult) parseResults(raw_result_set []interface{}) error {
 	header := raw_result_set[0]
-	qr.parseHeader(header)
-	qr.parseRecords(raw_result_set)
+	if err := qr.parseHeader(header); err != nil {
+		return err
+	}
+	return qr.parseRecords(raw_result_set)
 }
 
-func (qr *QueryResult) parseStatistics(raw_statistics interface{}) {
-	statistics, _ := redis.Strings(raw_statistics, nil)
+func (qr *QueryResult) parseStatistics(raw_statistics interface{}) error {
+	statistics, err := redis.Strings(raw_statistics, nil)
+	if err != nil {
+		return newParseError("malformed statistics", raw_statistics, err)
+	}
 	qr.statistics = make(map[string]float64)
 
 	for _, rs := range statistics {
-		v := strings.Split(rs, ": ")
-		f, _ := strconv.ParseFloat(strings.Split(v[1], " ")[0], 64)
+		v := strings.SplitN(rs, ": ", 2)
+		if len(v) != 2 {
+			return newParseError("malformed statistic", rs, nil)
+		}
+		f, err := strconv.ParseFloat(strings.Split(v[1], " ")[0], 64)
+		if err != nil {
+			return newParseError("malformed statistic", rs, err)
+		}
 		qr.statistics[v[0]] = f
 	}
+
+	return nil
 }
 
-func (qr *QueryResult) parseHeader(raw_header interface{}) {
-	header, _ := redis.Values(raw_header, nil)
+func (qr *QueryResult) parseHeader(raw_header interface{}) error {
+	header, err := redis.Values(raw_header, nil)
+	if err != nil {
+		return newParseError("malformed header", raw_header, err)
+	}
 
 	for _, col := range header {
-		c, _ := redis.Values(col, nil)
-		ct, _ := redis.Int(c[0], nil)
-		cn, _ := redis.String(c[1], nil)
+		c, err := redis.Values(col, nil)
+		if err != nil || len(c) != 2 {
+			return newParseError("malformed header column", col, err)
+		}
+		ct, err := redis.Int(c[0], nil)
+		if err != nil {
+			return newParseError("malformed header column type", col, err)
+		}
+		cn, err := redis.String(c[1], nil)
+		if err != nil {
+			return newParseError("malformed header column name", col, err)
+		}
 
 		qr.header.column_types = append(qr.header.column_types, ResultSetColumnTypes(ct))
 		qr.header.column_names = append(qr.header.column_names, cn)
 	}
+	qr.header.column_index = columnIndex(qr.header.column_names)
+
+	return nil
 }
 
-func (qr *QueryResult) parseRecords(raw_result_set []interface{}) {
-	records, _ := redis.Values(raw_result_set[1], nil)
+func (qr *QueryResult) parseRecords(raw_result_set []interface{}) error {
+	records, err := redis.Values(raw_result_set[1], nil)
+	if err != nil {
+		return newParseError("malformed records", raw_result_set[1], err)
+	}
+	if qr.lazy {
+		qr.pending = records
+		qr.rows = len(records)
+		return nil
+	}
 	qr.results = make([]*Record, len(records))
 
 	for i, r := range records {
-		cells, _ := redis.Values(r, nil)
-		values := make([]interface{}, len(cells))
-
-		for idx, c := range cells {
-			t := qr.header.column_types[idx]
-			switch t {
-			case COLUMN_SCALAR:
-				s, _ := redis.Values(c, nil)
-				values[idx] = qr.parseScalar(s)
-			case COLUMN_NODE:
-				values[idx] = qr.parseNode(c)
-			case COLUMN_RELATION:
-				values[idx] = qr.parseEdge(c)
-			default:
-				panic("Unknown column type.")
+		if qr.results[i], err = qr.parseRecord(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qr *QueryResult) parseRecord(r interface{}) (*Record, error) {
+	cells, err := redis.Values(r, nil)
+	if err != nil {
+		return nil, newParseError("malformed record", r, err)
+	}
+	if len(cells) != len(qr.header.column_types) {
+		return nil, newParseError("record length does not match header", r, nil)
+	}
+	values := make([]interface{}, len(cells))
+
+	for idx, c := range cells {
+		t := qr.header.column_types[idx]
+		switch t {
+		case COLUMN_SCALAR:
+			s, err := redis.Values(c, nil)
+			if err != nil {
+				return nil, newParseError("malformed scalar", c, err)
+			}
+			values[idx], err = qr.parseScalar(s)
+			if err != nil {
+				return nil, err
+			}
+		case COLUMN_NODE:
+			values[idx], err = qr.parseNode(c)
+			if err != nil {
+				return nil, err
 			}
+		case COLUMN_RELATION:
+			values[idx], err = qr.parseEdge(c)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, newParseError(fmt.Sprintf("unknown column type %d", t), c, nil)
 		}
-		qr.results[i] = recordNew(values, qr.header.column_names)
 	}
+	return recordNew(values, qr.header.column_names, qr.header.column_index), nil
+}
+
+// context returns the context of the query the result originates from.
+func (qr *QueryResult) context() context.Context {
+	if qr.ctx == nil {
+		return context.Background()
+	}
+	return qr.ctx
 }
 
-func (qr *QueryResult) parseProperties(props []interface{}) map[string]interface{} {
+func (qr *QueryResult) parseProperties(props []interface{}) (map[string]interface{}, error) {
 	// [[name, value type, value] X N]
 	properties := make(map[string]interface{})
 	for _, prop := range props {
-		p, _ := redis.Values(prop, nil)
-		idx, _ := redis.Int(p[0], nil)
-		prop_name := qr.graph.getProperty(idx)
-		prop_value := qr.parseScalar(p[1:])
+		p, err := redis.Values(prop, nil)
+		if err != nil || len(p) != 3 {
+			return nil, newParseError("malformed property", prop, err)
+		}
+		idx, err := redis.Int(p[0], nil)
+		if err != nil {
+			return nil, newParseError("malformed property key index", prop, err)
+		}
+		prop_name, err := qr.graph.getProperty(qr.context(), qr.lookupOptions, idx, prop)
+		if err != nil {
+			return nil, err
+		}
+		prop_value, err := qr.parseScalar(p[1:])
+		if err != nil {
+			return nil, err
+		}
 		properties[prop_name] = prop_value
 	}
 
-	return properties
+	return properties, nil
 }
 
-func (qr *QueryResult) parseNode(cell interface{}) *Node {
+func (qr *QueryResult) parseNode(cell interface{}) (*Node, error) {
 	// Node ID (integer),
 	// [label string offset (integer)],
 	// [[name, value type, value] X N]
 
-	c, _ := redis.Values(cell, nil)
-	id, _ := redis.Uint64(c[0], nil)
-	labelIds, _ := redis.Ints(c[1], nil)
+	c, err := redis.Values(cell, nil)
+	if err != nil || len(c) != 3 {
+		return nil, newParseError("malformed node", cell, err)
+	}
+	id, err := redis.Uint64(c[0], nil)
+	if err != nil {
+		return nil, newParseError("malformed node ID", cell, err)
+	}
+	labelIds, err := redis.Ints(c[1], nil)
+	if err != nil {
+		return nil, newParseError("malformed node labels", cell, err)
+	}
 	labels := make([]string, len(labelIds))
 	for i := 0; i < len(labelIds); i++ {
-		labels[i] = qr.graph.getLabel(labelIds[i])
+		if labels[i], err = qr.graph.getLabel(qr.context(), qr.lookupOptions, labelIds[i], cell); err != nil {
+			return nil, err
+		}
 	}
 
-	rawProps, _ := redis.Values(c[2], nil)
-	properties := qr.parseProperties(rawProps)
+	rawProps, err := redis.Values(c[2], nil)
+	if err != nil {
+		return nil, newParseError("malformed node properties", cell, err)
+	}
+	properties, err := qr.parseProperties(rawProps)
+	if err != nil {
+		return nil, err
+	}
 
 	n := NodeNew(labels, "", properties)
 	n.ID = id
-	return n
+	n.markCommitted()
+	return n, nil
 }
 
-func (qr *QueryResult) parseEdge(cell interface{}) *Edge {
+func (qr *QueryResult) parseEdge(cell interface{}) (*Edge, error) {
 	// Edge ID (integer),
 	// reltype string offset (integer),
 	// src node ID offset (integer),
 	// dest node ID offset (integer),
 	// [[name, value, value type] X N]
 
-	c, _ := redis.Values(cell, nil)
-	id, _ := redis.Uint64(c[0], nil)
-	r, _ := redis.Int(c[1], nil)
-	relation := qr.graph.getRelation(r)
+	c, err := redis.Values(cell, nil)
+	if err != nil || len(c) != 5 {
+		return nil, newParseError("malformed edge", cell, err)
+	}
+	id, err := redis.Uint64(c[0], nil)
+	if err != nil {
+		return nil, newParseError("malformed edge ID", cell, err)
+	}
+	r, err := redis.Int(c[1], nil)
+	if err != nil {
+		return nil, newParseError("malformed edge relationship type", cell, err)
+	}
+	relation, err := qr.graph.getRelation(qr.context(), qr.lookupOptions, r, cell)
+	if err != nil {
+		return nil, err
+	}
 
-	src_node_id, _ := redis.Uint64(c[2], nil)
-	dest_node_id, _ := redis.Uint64(c[3], nil)
-	rawProps, _ := redis.Values(c[4], nil)
-	properties := qr.parseProperties(rawProps)
+	src_node_id, err := redis.Uint64(c[2], nil)
+	if err != nil {
+		return nil, newParseError("malformed edge source node ID", cell, err)
+	}
+	dest_node_id, err := redis.Uint64(c[3], nil)
+	if err != nil {
+		return nil, newParseError("malformed edge destination node ID", cell, err)
+	}
+	rawProps, err := redis.Values(c[4], nil)
+	if err != nil {
+		return nil, newParseError("malformed edge properties", cell, err)
+	}
+	properties, err := qr.parseProperties(rawProps)
+	if err != nil {
+		return nil, err
+	}
 	e := EdgeNew(relation, nil, nil, properties)
 
 	e.ID = id
 	e.srcNodeID = src_node_id
 	e.destNodeID = dest_node_id
-	return e
+	e.markCommitted()
+	return e, nil
 }
 
-func (qr *QueryResult) parseArray(cell interface{}) []interface{} {
-	var array = cell.([]interface{})
+func (qr *QueryResult) parseArray(cell interface{}) ([]interface{}, error) {
+	array, err := redis.Values(cell, nil)
+	if err != nil {
+		return nil, newParseError("malformed array", cell, err)
+	}
 	var arrayLength = len(array)
 	for i := 0; i < arrayLength; i++ {
-		array[i] = qr.parseScalar(array[i].([]interface{}))
+		item, err := redis.Values(array[i], nil)
+		if err != nil {
+			return nil, newParseError("malformed array element", array[i], err)
+		}
+		if array[i], err = qr.parseScalar(item); err != nil {
+			return nil, err
+		}
 	}
-	return array
+	return array, nil
 }
 
-func (qr *QueryResult) parsePath(cell interface{}) Path {
-	arrays := cell.([]interface{})
-	nodes := qr.parseScalar(arrays[0].([]interface{}))
-	edges := qr.parseScalar(arrays[1].([]interface{}))
-	return PathNew(nodes.([]interface{}), edges.([]interface{}))
+func (qr *QueryResult) parsePath(cell interface{}) (Path, error) {
+	arrays, err := redis.Values(cell, nil)
+	if err != nil || len(arrays) != 2 {
+		return Path{}, newParseError("malformed path", cell, err)
+	}
+	rawNodes, err := redis.Values(arrays[0], nil)
+	if err != nil {
+		return Path{}, newParseError("malformed path nodes", cell, err)
+	}
+	rawEdges, err := redis.Values(arrays[1], nil)
+	if err != nil {
+		return Path{}, newParseError("malformed path edges", cell, err)
+	}
+	nodes, err := qr.parseScalar(rawNodes)
+	if err != nil {
+		return Path{}, err
+	}
+	edges, err := qr.parseScalar(rawEdges)
+	if err != nil {
+		return Path{}, err
+	}
+
+	nodeList, ok := nodes.([]interface{})
+	if !ok {
+		return Path{}, newParseError("path nodes are not an array", cell, nil)
+	}
+	edgeList, ok := edges.([]interface{})
+	if !ok {
+		return Path{}, newParseError("path edges are not an array", cell, nil)
+	}
+	for _, n := range nodeList {
+		if _, ok := n.(*Node); !ok {
+			return Path{}, newParseError("path contains a non-node element", cell, nil)
+		}
+	}
+	for _, e := range edgeList {
+		if _, ok := e.(*Edge); !ok {
+			return Path{}, newParseError("path contains a non-edge element", cell, nil)
+		}
+	}
+	return PathNew(nodeList, edgeList), nil
 }
 
-func (qr *QueryResult) parseMap(cell interface{}) map[string]interface{} {
-	var raw_map = cell.([]interface{})
+func (qr *QueryResult) parseMap(cell interface{}) (map[string]interface{}, error) {
+	raw_map, err := redis.Values(cell, nil)
+	if err != nil || len(raw_map)%2 != 0 {
+		return nil, newParseError("malformed map", cell, err)
+	}
 	var mapLength = len(raw_map)
 	var parsed_map = make(map[string]interface{})
 
 	for i := 0; i < mapLength; i += 2 {
-		key, _ := redis.String(raw_map[i], nil)
-		parsed_map[key] = qr.parseScalar(raw_map[i+1].([]interface{}))
+		key, err := redis.String(raw_map[i], nil)
+		if err != nil {
+			return nil, newParseError("malformed map key", raw_map[i], err)
+		}
+		value, err := redis.Values(raw_map[i+1], nil)
+		if err != nil {
+			return nil, newParseError("malformed map value", raw_map[i+1], err)
+		}
+		if parsed_map[key], err = qr.parseScalar(value); err != nil {
+			return nil, err
+		}
 	}
 
-	return parsed_map
+	return parsed_map, nil
 }
 
-func (qr *QueryResult) parseScalar(cell []interface{}) interface{} {
-	t, _ := redis.Int(cell[0], nil)
+func (qr *QueryResult) parseScalar(cell []interface{}) (interface{}, error) {
+	if len(cell) != 2 {
+		return nil, newParseError("malformed scalar", cell, nil)
+	}
+	t, err := redis.Int(cell[0], nil)
+	if err != nil {
+		return nil, newParseError("malformed scalar type", cell, err)
+	}
 	v := cell[1]
 	var s interface{}
 	switch ResultSetScalarTypes(t) {
 	case VALUE_NULL:
-		return nil
+		return nil, nil
 
 	case VALUE_STRING:
-		s, _ = redis.String(v, nil)
+		s, err = redis.String(v, nil)
 
 	case VALUE_INTEGER:
-		s, _ = redis.Int(v, nil)
+		if qr.integerDecoding() == INTEGER_INT64 {
+			s, err = redis.Int64(v, nil)
+		} else {
+			s, err = redis.Int(v, nil)
+		}
 
 	case VALUE_BOOLEAN:
-		s, _ = redis.Bool(v, nil)
+		s, err = redis.Bool(v, nil)
 
 	case VALUE_DOUBLE:
-		s, _ = redis.Float64(v, nil)
+		s, err = redis.Float64(v, nil)
 
 	case VALUE_ARRAY:
-		s = qr.parseArray(v)
+		return qr.parseArray(v)
 
 	case VALUE_EDGE:
-		s = qr.parseEdge(v)
+		return qr.parseEdge(v)
 
 	case VALUE_NODE:
-		s = qr.parseNode(v)
+		return qr.parseNode(v)
 
 	case VALUE_PATH:
-		s = qr.parsePath(v)
+		return qr.parsePath(v)
 
 	case VALUE_MAP:
-		s = qr.parseMap(v)
+		return qr.parseMap(v)
+
+	case VALUE_POINT:
+		var f []float64
+		if f, err = redis.Float64s(v, nil); err == nil {
+			if len(f) != 2 {
+				return nil, newParseError("malformed point", cell, nil)
+			}
+			s = Point{Latitude: f[0], Longitude: f[1]}
+		}
+
+	case VALUE_VECTORF32:
+		var f []float64
+		if f, err = redis.Float64s(v, nil); err == nil {
+			vec := make(Vector32, len(f))
+			for i := range f {
+				vec[i] = float32(f[i])
+			}
+			s = vec
+		}
+
+	case VALUE_DATETIME, VALUE_DATE, VALUE_TIME:
+		// Temporal values are reported as seconds since the epoch, times
+		// fall on January 1st 1970.
+		var secs int64
+		if secs, err = redis.Int64(v, nil); err == nil {
+			s = time.Unix(secs, 0).UTC()
+		}
+
+	case VALUE_DURATION:
+		var secs int64
+		if secs, err = redis.Int64(v, nil); err == nil {
+			s = time.Duration(secs) * time.Second
+		}
 
-	case VALUE_UNKNOWN:
-		panic("Unknown scalar type\n")
+	default:
+		return nil, newParseError(fmt.Sprintf("unknown scalar type %d", t), cell, nil)
 	}
 
-	return s
+	if err != nil {
+		return nil, newParseError(fmt.Sprintf("malformed scalar of type %d", t), cell, err)
+	}
+	return s, nil
+}
+
+// integerDecoding returns the Go type the result's integers decode to.
+func (qr *QueryResult) integerDecoding() IntegerDecoding {
+	if qr.graph != nil {
+		return qr.graph.integers
+	}
+	return qr.integers
 }
 
 func (qr *QueryResult) getStat(stat string) float64 {
@@ -290,7 +579,12 @@ func (qr *QueryResult) getStat(stat string) float64 {
 }
 
 // Next returns true only if there is a record to be processed.
+// For lazy results, see QueryOptions.SetLazy, Next decodes the record and
+// returns false should decoding fail, the error is reported by Err.
 func (qr *QueryResult) Next() bool {
+	if qr.lazy {
+		return qr.nextLazy()
+	}
 	if qr.Empty() {
 		return false
 	}
@@ -302,8 +596,51 @@ func (qr *QueryResult) Next() bool {
 	}
 }
 
+func (qr *QueryResult) nextLazy() bool {
+	qr.record = nil
+	if qr.err != nil || len(qr.pending) == 0 {
+		return false
+	}
+
+	r := qr.pending[0]
+	// Release the raw record as soon as it has been decoded.
+	qr.pending[0] = nil
+	qr.pending = qr.pending[1:]
+
+	qr.record, qr.err = qr.parseRecord(r)
+	return qr.err == nil
+}
+
+// Err returns the error met while decoding a lazy result's records, if any.
+func (qr *QueryResult) Err() error {
+	return qr.err
+}
+
+// forEachRecord calls fn for every record, lazy results are iterated from
+// their current position until exhausted.
+func (qr *QueryResult) forEachRecord(fn func(*Record) error) error {
+	if !qr.lazy {
+		for _, r := range qr.results {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for qr.Next() {
+		if err := fn(qr.record); err != nil {
+			return err
+		}
+	}
+	return qr.err
+}
+
 // Record returns the current record.
 func (qr *QueryResult) Record() *Record {
+	if qr.lazy {
+		return qr.record
+	}
 	if qr.currentRecordIdx >= 0 && qr.currentRecordIdx < len(qr.results) {
 		return qr.results[qr.currentRecordIdx]
 	} else {
@@ -311,37 +648,9 @@ func (qr *QueryResult) Record() *Record {
 	}
 }
 
-// PrettyPrint prints the QueryResult to stdout, pretty-like.
+// PrettyPrint prints the QueryResult to stdout as a table, see Render.
 func (qr *QueryResult) PrettyPrint() {
-	if qr.Empty() {
-		return
-	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetAutoFormatHeaders(false)
-	table.SetHeader(qr.header.column_names)
-	row_count := len(qr.results)
-	col_count := len(qr.header.column_names)
-	if len(qr.results) > 0 {
-		// Convert to [][]string.
-		results := make([][]string, row_count)
-		for i, record := range qr.results {
-			results[i] = make([]string, col_count)
-			for j, elem := range record.Values() {
-				results[i][j] = fmt.Sprint(elem)
-			}
-		}
-		table.AppendBulk(results)
-	} else {
-		table.Append([]string{"No data returned."})
-	}
-	table.Render()
-
-	for k, v := range qr.statistics {
-		fmt.Fprintf(os.Stdout, "\n%s %f", k, v)
-	}
-
-	fmt.Fprintf(os.Stdout, "\n")
+	qr.Render(os.Stdout, FORMAT_TABLE)
 }
 
 func (qr *QueryResult) LabelsAdded() int {
@@ -1,19 +1,37 @@
 package redisgraph
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
 type Record struct {
 	values	[]interface{}
 	keys	[]string
+	index	map[string]int
 }
 
-func recordNew(values []interface{}, keys []string) *Record {
+func recordNew(values []interface{}, keys []string, index map[string]int) *Record {
 	r := &Record {
 		values: values,
 		keys: keys,
+		index: index,
 	}
 
 	return r
 }
 
+// columnIndex maps column names to their position, the first column wins
+// should several share a name.
+func columnIndex(keys []string) map[string]int {
+	index := make(map[string]int, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		index[keys[i]] = i
+	}
+	return index
+}
+
 func (r *Record) Keys() []string {
 	return r.keys
 }
@@ -23,11 +41,8 @@ func (r *Record) Values() []interface{} {
 }
 
 func (r *Record) Get(key string) (interface{}, bool) {
-	// TODO: switch from []string to map[string]int
-	for i := range r.keys {
-		if r.keys[i] == key {
-			return r.values[i], true
-		}
+	if i, ok := r.index[key]; ok {
+		return r.values[i], true
 	}
 	return nil, false
 }
@@ -39,3 +54,250 @@ func (r *Record) GetByIndex(index int) interface{} {
 		return nil
 	}
 }
+
+// ColumnError is returned by Record's typed accessors when the requested
+// column does not exist.
+type ColumnError struct {
+	Key   string // Requested column name, empty when accessed by index.
+	Index int    // Requested column index, -1 when accessed by name.
+}
+
+func (e *ColumnError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("redisgraph: record has no column %q", e.Key)
+	}
+	return fmt.Sprintf("redisgraph: column index %d out of range", e.Index)
+}
+
+// TypeError is returned by Record's typed accessors when a value is not of
+// the requested type.
+type TypeError struct {
+	Column string       // Column name.
+	Index  int          // Column index.
+	Type   reflect.Type // Requested type.
+	Value  interface{}  // Value held by the column.
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("redisgraph: column %q (index %d) holds %T %v, not %v", e.Column, e.Index, e.Value, e.Value, e.Type)
+}
+
+// ErrNull is returned by Record's typed accessors when the requested column
+// holds null.
+var ErrNull = errors.New("redisgraph: column value is null")
+
+var (
+	stringType  = reflect.TypeOf("")
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	boolType    = reflect.TypeOf(false)
+	nodeType    = reflect.TypeOf(&Node{})
+	edgeType    = reflect.TypeOf(&Edge{})
+	pathType    = reflect.TypeOf(Path{})
+	listType    = reflect.TypeOf([]interface{}{})
+	mapType     = reflect.TypeOf(map[string]interface{}{})
+)
+
+// GetString returns the string held by column key.
+//
+// The typed accessors do not convert values, unlike Record.Scan: a value of
+// another type is reported as a *TypeError, null as ErrNull and a missing
+// column as a *ColumnError.
+func (r *Record) GetString(key string) (string, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return "", err
+	}
+	return r.GetStringByIndex(i)
+}
+
+// GetStringByIndex returns the string held by column index.
+func (r *Record) GetStringByIndex(index int) (string, error) {
+	v, err := r.at(index, stringType)
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// GetInt64 returns the integer held by column key, regardless of whether
+// integers decode as int or int64, see Graph.SetIntegerDecoding.
+func (r *Record) GetInt64(key string) (int64, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return 0, err
+	}
+	return r.GetInt64ByIndex(i)
+}
+
+// GetInt64ByIndex returns the integer held by column index.
+func (r *Record) GetInt64ByIndex(index int) (int64, error) {
+	if err := r.checkIndex(index); err != nil {
+		return 0, err
+	}
+	if i, ok := r.values[index].(int); ok {
+		return int64(i), nil
+	}
+	v, err := r.at(index, int64Type)
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// GetFloat64 returns the float held by column key.
+func (r *Record) GetFloat64(key string) (float64, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return 0, err
+	}
+	return r.GetFloat64ByIndex(i)
+}
+
+// GetFloat64ByIndex returns the float held by column index.
+func (r *Record) GetFloat64ByIndex(index int) (float64, error) {
+	v, err := r.at(index, float64Type)
+	if err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
+
+// GetBool returns the boolean held by column key.
+func (r *Record) GetBool(key string) (bool, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return false, err
+	}
+	return r.GetBoolByIndex(i)
+}
+
+// GetBoolByIndex returns the boolean held by column index.
+func (r *Record) GetBoolByIndex(index int) (bool, error) {
+	v, err := r.at(index, boolType)
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+// GetNode returns the node held by column key.
+func (r *Record) GetNode(key string) (*Node, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetNodeByIndex(i)
+}
+
+// GetNodeByIndex returns the node held by column index.
+func (r *Record) GetNodeByIndex(index int) (*Node, error) {
+	v, err := r.at(index, nodeType)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Node), nil
+}
+
+// GetEdge returns the edge held by column key.
+func (r *Record) GetEdge(key string) (*Edge, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetEdgeByIndex(i)
+}
+
+// GetEdgeByIndex returns the edge held by column index.
+func (r *Record) GetEdgeByIndex(index int) (*Edge, error) {
+	v, err := r.at(index, edgeType)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Edge), nil
+}
+
+// GetPath returns the path held by column key.
+func (r *Record) GetPath(key string) (Path, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return Path{}, err
+	}
+	return r.GetPathByIndex(i)
+}
+
+// GetPathByIndex returns the path held by column index.
+func (r *Record) GetPathByIndex(index int) (Path, error) {
+	v, err := r.at(index, pathType)
+	if err != nil {
+		return Path{}, err
+	}
+	return v.(Path), nil
+}
+
+// GetList returns the list held by column key.
+func (r *Record) GetList(key string) ([]interface{}, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetListByIndex(i)
+}
+
+// GetListByIndex returns the list held by column index.
+func (r *Record) GetListByIndex(index int) ([]interface{}, error) {
+	v, err := r.at(index, listType)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]interface{}), nil
+}
+
+// GetMap returns the map held by column key.
+func (r *Record) GetMap(key string) (map[string]interface{}, error) {
+	i, err := r.column(key)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetMapByIndex(i)
+}
+
+// GetMapByIndex returns the map held by column index.
+func (r *Record) GetMapByIndex(index int) (map[string]interface{}, error) {
+	v, err := r.at(index, mapType)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+// column returns the index of column key.
+func (r *Record) column(key string) (int, error) {
+	i, ok := r.index[key]
+	if !ok {
+		return 0, &ColumnError{Key: key, Index: -1}
+	}
+	return i, nil
+}
+
+func (r *Record) checkIndex(index int) error {
+	if index < 0 || index >= len(r.values) {
+		return &ColumnError{Index: index}
+	}
+	return nil
+}
+
+// at returns the value of column index, which must be of type typ.
+func (r *Record) at(index int, typ reflect.Type) (interface{}, error) {
+	if err := r.checkIndex(index); err != nil {
+		return nil, err
+	}
+	v := r.values[index]
+	if v == nil {
+		return nil, ErrNull
+	}
+	if reflect.TypeOf(v) != typ {
+		return nil, &TypeError{Column: r.keys[index], Index: index, Type: typ, Value: v}
+	}
+	return v, nil
+}
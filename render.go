@@ -0,0 +1,254 @@
+package redisgraph
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderFormat is an output format of QueryResult.Render.
+type RenderFormat int
+
+const (
+	FORMAT_TABLE    RenderFormat = iota // ASCII table followed by statistics.
+	FORMAT_MARKDOWN                     // Markdown table followed by statistics.
+	FORMAT_CSV                          // Comma separated values, header first.
+	FORMAT_TSV                          // Tab separated values, header first.
+	FORMAT_JSONL                        // One JSON object per record, keyed by column.
+)
+
+// Render writes the result to w in the given format. Tables are followed by
+// the query statistics, ordered by name, while the other formats hold
+// records only. Lazy results have their remaining records consumed.
+func (qr *QueryResult) Render(w io.Writer, format RenderFormat) error {
+	switch format {
+	case FORMAT_TABLE:
+		return qr.renderTable(w)
+	case FORMAT_MARKDOWN:
+		return qr.renderMarkdown(w)
+	case FORMAT_CSV:
+		return qr.renderCSV(w, ',')
+	case FORMAT_TSV:
+		return qr.renderCSV(w, '\t')
+	case FORMAT_JSONL:
+		return qr.renderJSONL(w)
+	}
+	return fmt.Errorf("redisgraph: unknown render format %d", format)
+}
+
+// textRows returns the remaining records rendered as text.
+func (qr *QueryResult) textRows() ([][]string, error) {
+	var rows [][]string
+	err := qr.forEachRecord(func(r *Record) error {
+		row := make([]string, len(r.values))
+		for i, v := range r.values {
+			row[i] = renderValue(v, false)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+func (qr *QueryResult) renderTable(w io.Writer) error {
+	if len(qr.header.column_names) > 0 {
+		rows, err := qr.textRows()
+		if err != nil {
+			return err
+		}
+		table := tablewriter.NewWriter(w)
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		table.SetHeader(qr.header.column_names)
+		table.AppendBulk(rows)
+		table.Render()
+	}
+	return qr.renderStatistics(w)
+}
+
+func (qr *QueryResult) renderMarkdown(w io.Writer) error {
+	if len(qr.header.column_names) > 0 {
+		rows, err := qr.textRows()
+		if err != nil {
+			return err
+		}
+		separator := make([]string, len(qr.header.column_names))
+		for i := range separator {
+			separator[i] = "---"
+		}
+		lines := append([][]string{qr.header.column_names, separator}, rows...)
+		for i, line := range lines {
+			cells := make([]string, len(line))
+			for j, c := range line {
+				if i != 1 {
+					c = escapeMarkdown(c)
+				}
+				cells[j] = c
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+				return err
+			}
+		}
+	}
+	return qr.renderStatistics(w)
+}
+
+// escapeMarkdown escapes s for use within a Markdown table cell.
+func escapeMarkdown(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	s = strings.Replace(s, "\r\n", "<br>", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+func (qr *QueryResult) renderStatistics(w io.Writer) error {
+	keys := make([]string, 0, len(qr.statistics))
+	for k := range qr.statistics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i == 0 && len(qr.header.column_names) > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", k, strconv.FormatFloat(qr.statistics[k], 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (qr *QueryResult) renderCSV(w io.Writer, comma rune) error {
+	if len(qr.header.column_names) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(qr.header.column_names); err != nil {
+		return err
+	}
+	err := qr.forEachRecord(func(r *Record) error {
+		row := make([]string, len(r.values))
+		for i, v := range r.values {
+			// Nulls are left empty.
+			if v != nil {
+				row[i] = renderValue(v, false)
+			}
+		}
+		return cw.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (qr *QueryResult) renderJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return qr.forEachRecord(func(r *Record) error {
+		obj := make(map[string]interface{}, len(r.values))
+		for i, v := range r.values {
+			// The first of duplicate columns wins, as with Record.Get.
+			if _, ok := obj[r.keys[i]]; !ok {
+				obj[r.keys[i]] = jsonValue(v)
+			}
+		}
+		return enc.Encode(obj)
+	})
+}
+
+// renderValue renders v as text, strings nested in lists and maps are quoted.
+func renderValue(v interface{}, nested bool) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if nested {
+			return quoteString(v)
+		}
+		return v
+	case *Node:
+		return renderNode(v)
+	case *Edge:
+		return fmt.Sprintf("(%d)-%s->(%d)", v.SourceNodeID(), renderEdge(v), v.DestNodeID())
+	case Path:
+		return renderPath(v)
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, e := range v {
+			elems[i] = renderValue(e, true)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = quoteIdentifier(k) + ": " + renderValue(v[k], true)
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
+
+// renderNode renders n as (id:Label {properties}).
+func renderNode(n *Node) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "(%d", n.ID)
+	for _, l := range n.Labels {
+		sb.WriteString(":" + quoteIdentifier(l))
+	}
+	sb.WriteString(renderProperties(n.Properties))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// renderEdge renders e as [id:TYPE {properties}].
+func renderEdge(e *Edge) string {
+	s := fmt.Sprintf("[%d", e.ID)
+	if e.Relation != "" {
+		s += ":" + quoteIdentifier(e.Relation)
+	}
+	return s + renderProperties(e.Properties) + "]"
+}
+
+func renderPath(p Path) string {
+	if len(p.Nodes) == 0 {
+		return "<>"
+	}
+	var sb strings.Builder
+	sb.WriteString("<")
+	for i, n := range p.Nodes {
+		sb.WriteString(renderNode(n))
+		if i >= len(p.Edges) {
+			break
+		}
+		e := p.Edges[i]
+		if e.SourceNodeID() == n.ID {
+			sb.WriteString("-" + renderEdge(e) + "->")
+		} else {
+			sb.WriteString("<-" + renderEdge(e) + "-")
+		}
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+func renderProperties(props map[string]interface{}) string {
+	if len(props) == 0 {
+		return ""
+	}
+	return " " + renderValue(props, true)
+}
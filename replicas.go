@@ -0,0 +1,129 @@
+package redisgraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// replicaRetryInterval is how long a replica which failed is left out of
+// rotation before being tried again.
+const replicaRetryInterval = 5 * time.Second
+
+// replicaSet holds the replicas read only queries are balanced across.
+type replicaSet struct {
+	lastWrite int64  // Time of the latest write, in Unix nanoseconds.
+	next      uint32 // Round robin counter.
+	replicas  []*replica
+}
+
+type replica struct {
+	provider  ConnProvider
+	downUntil int64 // Unix nanoseconds until which the replica is skipped.
+}
+
+// SetReplicas makes read only queries issued by ROQuery, ROQueryWithOptions
+// and their Context variants go to replicas, in turn, rather than to the
+// graph's own connection, the primary. A replica failing to serve a query,
+// because it cannot be reached or is still loading its data, is skipped for
+// a few seconds and the query is issued against the next one, falling back
+// to the primary when none is healthy. Pipelines and transactions always use
+// the primary. Calling SetReplicas with no replicas sends every query to the
+// primary again. It must not be called while the graph is in use.
+func (g *Graph) SetReplicas(replicas ...ConnProvider) {
+	if len(replicas) == 0 {
+		g.replicas = nil
+		return
+	}
+	rs := &replicaSet{replicas: make([]*replica, len(replicas))}
+	for i, p := range replicas {
+		rs.replicas[i] = &replica{provider: p}
+	}
+	g.replicas = rs
+}
+
+// SetReadYourWrites pins read only queries to the primary for window after
+// every successful write issued through the graph, so that they observe it
+// even though replicas may lag behind. Writes are queries issued by Query,
+// Commit, pipelines and transactions, deletions, bulk loads and configuration
+// changes. A zero window, the default, disables pinning. It must not be
+// called while the graph is in use.
+func (g *Graph) SetReadYourWrites(window time.Duration) {
+	g.readYourWrites = window
+}
+
+// isWriteCommand reports whether cmd, issued with args, may modify a graph.
+func isWriteCommand(cmd string, args []interface{}) bool {
+	switch cmd {
+	case "GRAPH.QUERY", "GRAPH.DELETE", "GRAPH.BULK", "GRAPH.COPY":
+		return true
+	case "GRAPH.CONFIG":
+		return len(args) > 0 && strings.EqualFold(fmt.Sprint(args[0]), "SET")
+	}
+	return false
+}
+
+// wrote records that a write has been applied by the primary.
+func (g *Graph) wrote() {
+	if g.replicas != nil && g.readYourWrites > 0 {
+		atomic.StoreInt64(&g.replicas.lastWrite, time.Now().UnixNano())
+	}
+}
+
+// pinned reports whether read only queries must go to the primary, as a write
+// happened within the read your writes window.
+func (g *Graph) pinned() bool {
+	if g.readYourWrites <= 0 {
+		return false
+	}
+	last := atomic.LoadInt64(&g.replicas.lastWrite)
+	return last != 0 && time.Since(time.Unix(0, last)) < g.readYourWrites
+}
+
+// doReplica issues a read only command against the next healthy replica,
+// or the primary when there is none.
+func (g *Graph) doReplica(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	rs := g.replicas
+	n := uint32(len(rs.replicas))
+	start := atomic.AddUint32(&rs.next, 1)
+	for i := uint32(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if atomic.LoadInt64(&r.downUntil) > time.Now().UnixNano() {
+			continue
+		}
+		res, err := r.do(ctx, cmd, args...)
+		if !replicaFailed(err) {
+			return res, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		atomic.StoreInt64(&r.downUntil, time.Now().Add(replicaRetryInterval).UnixNano())
+	}
+	return g.doPrimary(ctx, cmd, args...)
+}
+
+func (r *replica) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	conn, err := getProviderConn(ctx, r.provider)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return doContext(ctx, conn, cmd, args...)
+}
+
+// replicaFailed reports whether err indicates the replica is unable to serve
+// queries, rather than the query itself having failed.
+func replicaFailed(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case redis.Error:
+		return strings.HasPrefix(string(e), "LOADING") || strings.HasPrefix(string(e), "MASTERDOWN")
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package redisgraph
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// RetryPolicy determines which failed commands are issued again, how many
+// times and how long to wait in between. A policy must not be modified once
+// in use, it may be shared by multiple graphs.
+type RetryPolicy struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	retryWrites bool
+	classifier  func(err error) bool
+	onRetry     func(cmd string, attempt int, delay time.Duration, err error)
+}
+
+// NewRetryPolicy instantiates a retry policy making up to 3 attempts, backing
+// off from 100ms up to 2s, and retrying read only queries failing with an
+// error reported by IsRetryable.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		maxAttempts: 3,
+		minBackoff:  100 * time.Millisecond,
+		maxBackoff:  2 * time.Second,
+		classifier:  IsRetryable,
+	}
+}
+
+// SetMaxAttempts sets the number of times a command is issued at most,
+// including the first attempt.
+func (p *RetryPolicy) SetMaxAttempts(attempts int) *RetryPolicy {
+	p.maxAttempts = attempts
+	return p
+}
+
+// GetMaxAttempts retrieves the number of times a command is issued at most.
+func (p *RetryPolicy) GetMaxAttempts() int {
+	return p.maxAttempts
+}
+
+// SetBackoff sets the delays before retrying, the delay starts at min and
+// doubles with every attempt, up to max. A random jitter of up to half the
+// delay is subtracted, spreading retries of concurrent callers apart.
+func (p *RetryPolicy) SetBackoff(min, max time.Duration) *RetryPolicy {
+	p.minBackoff, p.maxBackoff = min, max
+	return p
+}
+
+// GetBackoff retrieves the minimum and maximum delays before retrying.
+func (p *RetryPolicy) GetBackoff() (min, max time.Duration) {
+	return p.minBackoff, p.maxBackoff
+}
+
+// SetRetryWrites makes every command issued by the graph eligible for retry,
+// not only read only queries. A write whose reply was lost, e.g. to a reset
+// connection, may have been applied and is applied again when retried.
+func (p *RetryPolicy) SetRetryWrites(enabled bool) *RetryPolicy {
+	p.retryWrites = enabled
+	return p
+}
+
+// GetRetryWrites reports whether writes are retried.
+func (p *RetryPolicy) GetRetryWrites() bool {
+	return p.retryWrites
+}
+
+// SetClassifier sets the function reporting whether a command failing with
+// err should be retried, IsRetryable by default.
+func (p *RetryPolicy) SetClassifier(classifier func(err error) bool) *RetryPolicy {
+	p.classifier = classifier
+	return p
+}
+
+// SetOnRetry sets a hook called before each retry, with the command, the
+// number of the attempt which failed, starting at 1, the delay until the
+// next attempt and the error the attempt failed with.
+func (p *RetryPolicy) SetOnRetry(hook func(cmd string, attempt int, delay time.Duration, err error)) *RetryPolicy {
+	p.onRetry = hook
+	return p
+}
+
+// IsRetryable reports whether err is likely transient: the server is loading
+// its dataset, has too many pending queries, or was demoted to a replica by
+// a failover, or the connection to it failed. Errors wrapping one of these,
+// through an Unwrap method, are retryable too.
+func IsRetryable(err error) bool {
+	for err != nil {
+		if isTransient(err) {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// isTransient reports whether err, as is, is likely transient.
+func isTransient(err error) bool {
+	switch e := err.(type) {
+	case redis.Error:
+		s := string(e)
+		return strings.HasPrefix(s, "LOADING") ||
+			strings.HasPrefix(s, "READONLY") ||
+			strings.HasPrefix(s, "MASTERDOWN") ||
+			strings.HasPrefix(s, "TRYAGAIN") ||
+			strings.Contains(s, "Max pending queries exceeded")
+	case *ReadOnlyError:
+		return true
+	case net.Error:
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// retries reports whether cmd is eligible for retry.
+func (p *RetryPolicy) retries(cmd string) bool {
+	return p.retryWrites || cmd == "GRAPH.RO_QUERY"
+}
+
+// backoff returns the delay following the given failed attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.minBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// SetRetryPolicy sets the policy commands failing with transient errors are
+// retried under, no command is retried by default. Pipelines and transactions
+// are never retried, nor are commands of a graph created by GraphNew once its
+// connection is broken, as it can not be replaced. It must not be called
+// while the graph is in use.
+func (g *Graph) SetRetryPolicy(policy *RetryPolicy) {
+	g.retry = policy
+}
+
+// doRetry issues cmd, retrying it as the graph's retry policy permits.
+func (g *Graph) doRetry(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	p := g.retry
+	for attempt := 1; ; attempt++ {
+		r, err := g.route(ctx, cmd, args...)
+		if err == nil || p == nil || attempt >= p.maxAttempts || !p.retries(cmd) ||
+			ctx.Err() != nil || !p.classifier(err) || g.connBroken() {
+			return r, err
+		}
+
+		delay := p.backoff(attempt)
+		if p.onRetry != nil {
+			p.onRetry(cmd, attempt, delay, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// connBroken reports whether the graph issues commands over its own
+// connection, rather than a pool or an Executor, and that connection failed.
+func (g *Graph) connBroken() bool {
+	if g.pool != nil || g.exec != nil {
+		return false
+	}
+	g.connMutex.Lock()
+	defer g.connMutex.Unlock()
+	return g.Conn == nil || g.Conn.Err() != nil
+}
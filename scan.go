@@ -0,0 +1,461 @@
+package redisgraph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ScanError is returned when a value can not be stored in a scan destination.
+type ScanError struct {
+	Column string       // Column the value originates from.
+	Field  string       // Destination struct field path, empty for plain destinations.
+	Type   reflect.Type // Expected Go type.
+	Value  interface{}  // Value which failed to convert.
+	Err    error        // Underlying conversion error, may be nil.
+}
+
+func (e *ScanError) Error() string {
+	target := fmt.Sprintf("column %q", e.Column)
+	if e.Field != "" {
+		target += fmt.Sprintf(" (field %s)", e.Field)
+	}
+	msg := fmt.Sprintf("redisgraph: %s: cannot convert %T %v to %v", target, e.Value, e.Value, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+var errNotPointer = errors.New("destination must be a non-nil pointer")
+
+// Scan copies the record's values into dest.
+//
+// Either a destination is given per column, each a pointer converted into
+// following the rules of ScanAll, or a single pointer to a struct is given.
+// In the latter case a record holding one node, edge or map has its properties
+// decoded into the struct, otherwise columns are mapped onto struct fields by
+// their `redisgraph:"name"` tag, falling back to a case-insensitive match on
+// the field name, at least one field must match. Pointers to time.Time,
+// Point and CypherValue implementations are plain destinations.
+func (r *Record) Scan(dest ...interface{}) error {
+	if len(dest) == 1 && isStructPointer(dest[0]) && !r.singleEntity() {
+		return r.scanStruct(dest[0])
+	}
+
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("redisgraph: expected %d destination arguments in Scan, got %d", len(r.values), len(dest))
+	}
+
+	for i, d := range dest {
+		v := reflect.ValueOf(d)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return &ScanError{Column: r.keys[i], Type: reflect.TypeOf(d), Value: r.values[i], Err: errNotPointer}
+		}
+		if err := assign(v.Elem(), r.values[i]); err != nil {
+			return wrapScanError(err, r.keys[i], v.Elem().Type(), r.values[i])
+		}
+	}
+	return nil
+}
+
+// singleEntity reports whether the record consists of a single node, edge or map.
+func (r *Record) singleEntity() bool {
+	if len(r.values) != 1 {
+		return false
+	}
+	switch r.values[0].(type) {
+	case *Node, *Edge, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// scanStruct maps the record's columns onto the fields of the struct dest points to.
+func (r *Record) scanStruct(dest interface{}) error {
+	v := reflect.ValueOf(dest).Elem()
+	fields := structFields(v.Type())
+
+	matched := false
+	for i, key := range r.keys {
+		idx, ok := fields[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		matched = true
+		f := v.FieldByIndex(idx)
+		if err := assign(f, r.values[i]); err != nil {
+			return wrapScanError(err, key, f.Type(), r.values[i])
+		}
+	}
+	if !matched && len(r.keys) > 0 {
+		return fmt.Errorf("redisgraph: %v has no exported field matching any of the columns %q", v.Type(), r.keys)
+	}
+	return nil
+}
+
+// ScanAll decodes every record of the result set into dest, which must be a
+// pointer to a slice. Slice elements of struct type, or pointers to structs,
+// are filled as in Record.Scan, any other element type requires the result set
+// to have a single column.
+//
+// Values are converted between numeric types when no precision is lost,
+// numbers and booleans convert to strings, lists convert to slices and nodes,
+// edges and maps convert to structs and maps. Iterating with Next is not
+// affected by ScanAll, except for lazy results whose remaining records are
+// consumed.
+func (qr *QueryResult) ScanAll(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("redisgraph: ScanAll expects a pointer to a slice, got %T", dest)
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	out := reflect.MakeSlice(slice.Type(), 0, len(qr.results))
+
+	err := qr.forEachRecord(func(record *Record) error {
+		elem := reflect.New(elemType)
+		target := elem.Interface()
+		if elemType.Kind() == reflect.Ptr {
+			// Allocate the value the pointer element points to.
+			elem.Elem().Set(reflect.New(elemType.Elem()))
+			target = elem.Elem().Interface()
+		}
+
+		if err := record.Scan(target); err != nil {
+			return err
+		}
+		out = reflect.Append(out, elem.Elem())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slice.Set(out)
+	return nil
+}
+
+func isStructPointer(dest interface{}) bool {
+	t := reflect.TypeOf(dest)
+	return t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !isValueType(t.Elem())
+}
+
+// isValueType reports whether values of struct type t are assigned as a
+// whole rather than decoded field by field: graph entities, points,
+// time.Time and CypherValue implementations.
+func isValueType(t reflect.Type) bool {
+	if isEntityType(t) || t == timeType || t == reflect.TypeOf(Point{}) {
+		return true
+	}
+	return t.Implements(cypherValueType) || reflect.PtrTo(t).Implements(cypherValueType)
+}
+
+// isEntityType reports whether t is one of the graph entity types which are
+// assigned as is rather than decoded field by field.
+func isEntityType(t reflect.Type) bool {
+	return t == reflect.TypeOf(Node{}) || t == reflect.TypeOf(Edge{}) || t == reflect.TypeOf(Path{})
+}
+
+// structFields maps lower cased column names onto the index of the struct
+// field they are decoded into, descending into embedded structs.
+func structFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("redisgraph")
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for name, idx := range structFields(f.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = append([]int{i}, idx...)
+				}
+			}
+			continue
+		}
+
+		// Skip unexported fields.
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = []int{i}
+	}
+	return fields
+}
+
+// fieldError reports a failure to decode a struct field, the field path is
+// accumulated as the error propagates up through nested structs.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+// conversionError reports a value which can not be converted to the destination type.
+type conversionError struct {
+	typ   reflect.Type
+	value interface{}
+	err   error
+}
+
+func (e *conversionError) Error() string {
+	return fmt.Sprintf("cannot convert %T to %v", e.value, e.typ)
+}
+
+func wrapScanError(err error, column string, typ reflect.Type, value interface{}) error {
+	se := &ScanError{Column: column, Type: typ, Value: value}
+	for {
+		switch e := err.(type) {
+		case *fieldError:
+			if se.Field == "" {
+				se.Field = e.field
+			} else {
+				se.Field = e.field + "." + se.Field
+			}
+			err = e.err
+			continue
+		case *conversionError:
+			se.Type = e.typ
+			se.Value = e.value
+			se.Err = e.err
+		default:
+			se.Err = err
+		}
+		return se
+	}
+}
+
+func mismatch(dst reflect.Value, src interface{}) error {
+	return &conversionError{typ: dst.Type(), value: src}
+}
+
+// assign stores src into dst, converting between types where safe.
+func assign(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		v := reflect.New(dst.Type().Elem())
+		if err := assign(v.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(src)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		if dst.OverflowInt(i) {
+			return &conversionError{typ: dst.Type(), value: src, err: strconv.ErrRange}
+		}
+		dst.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := src.(uint64)
+		if !ok {
+			i, isInt := toInt64(src)
+			if !isInt {
+				return mismatch(dst, src)
+			}
+			if i < 0 {
+				return &conversionError{typ: dst.Type(), value: src, err: strconv.ErrRange}
+			}
+			u = uint64(i)
+		}
+		if dst.OverflowUint(u) {
+			return &conversionError{typ: dst.Type(), value: src, err: strconv.ErrRange}
+		}
+		dst.SetUint(u)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(src)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		if dst.Kind() == reflect.Float32 && float64(float32(f)) != f && !math.IsNaN(f) {
+			return &conversionError{typ: dst.Type(), value: src, err: strconv.ErrRange}
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		dst.SetBool(b)
+		return nil
+
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+		case bool:
+			dst.SetString(strconv.FormatBool(s))
+		case float64:
+			dst.SetString(strconv.FormatFloat(s, 'f', -1, 64))
+		default:
+			i, ok := toInt64(src)
+			if !ok {
+				return mismatch(dst, src)
+			}
+			dst.SetString(strconv.FormatInt(i, 10))
+		}
+		return nil
+
+	case reflect.Slice:
+		if s, ok := src.(string); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+		items, ok := src.([]interface{})
+		if !ok {
+			return mismatch(dst, src)
+		}
+		out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(out.Index(i), item); err != nil {
+				return &fieldError{field: fmt.Sprintf("[%d]", i), err: err}
+			}
+		}
+		dst.Set(out)
+		return nil
+
+	case reflect.Map:
+		props, ok := properties(src)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch(dst, src)
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(props))
+		for k, item := range props {
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(v, item); err != nil {
+				return &fieldError{field: fmt.Sprintf("[%q]", k), err: err}
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), v)
+		}
+		dst.Set(out)
+		return nil
+
+	case reflect.Struct:
+		if isEntityType(dst.Type()) && sv.Kind() == reflect.Ptr && sv.Elem().Type() == dst.Type() {
+			dst.Set(sv.Elem())
+			return nil
+		}
+		props, ok := properties(src)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		return assignStruct(dst, props)
+	}
+
+	return mismatch(dst, src)
+}
+
+// assignStruct decodes props into the fields of struct dst.
+func assignStruct(dst reflect.Value, props map[string]interface{}) error {
+	fields := structFields(dst.Type())
+	for key, value := range props {
+		idx, ok := fields[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		if err := assign(dst.FieldByIndex(idx), value); err != nil {
+			return &fieldError{field: dst.Type().FieldByIndex(idx).Name, err: err}
+		}
+	}
+	return nil
+}
+
+// properties returns the key value pairs held by a node, edge or map.
+func properties(src interface{}) (map[string]interface{}, bool) {
+	switch v := src.(type) {
+	case *Node:
+		return v.Properties, true
+	case *Edge:
+		return v.Properties, true
+	case map[string]interface{}:
+		return v, true
+	}
+	return nil, false
+}
+
+func toInt64(src interface{}) (int64, bool) {
+	switch v := src.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func toFloat64(src interface{}) (float64, bool) {
+	switch v := src.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	i, ok := toInt64(src)
+	// Integers beyond 2^53 can not be represented exactly.
+	if !ok || i > 1<<53 || i < -(1<<53) {
+		return 0, false
+	}
+	return float64(i), true
+}
@@ -0,0 +1,253 @@
+package redisgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ReadOnlyError is returned when a write reaches a node which has been
+// demoted to a replica, typically while the master fails over. Connections
+// obtained afterwards are made to the newly elected master.
+type ReadOnlyError struct {
+	Addr string      // Address of the demoted node.
+	Err  redis.Error // Reply of the node.
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("redisgraph: %s is a read-only replica, the master may have failed over: %v", e.Addr, e.Err)
+}
+
+// Unwrap returns the node's reply.
+func (e *ReadOnlyError) Unwrap() error {
+	return e.Err
+}
+
+// Sentinel is a ConnProvider connecting to the current master of a service
+// monitored by Redis Sentinel. The master's address is resolved through the
+// sentinels, the node being required to report the master role, and
+// resolved again whenever a connection fails or the node reports having
+// become a replica, so that commands reach the new master after a failover.
+// A Sentinel is safe for concurrent use.
+type Sentinel struct {
+	sentinels []string
+	service   string
+	dial      func(addr string) (redis.Conn, error)
+	mutex     sync.Mutex
+	addr      string      // Address of the master, empty until resolved.
+	pool      *redis.Pool // Connections to the master.
+}
+
+// SentinelNew returns a Sentinel connecting to the master of service, as
+// reported by the first reachable of sentinels, given in host:port form.
+// dial connects to both sentinels and Redis nodes, applying any
+// authentication or timeouts required.
+func SentinelNew(sentinels []string, service string, dial func(addr string) (redis.Conn, error)) *Sentinel {
+	return &Sentinel{
+		sentinels: sentinels,
+		service:   service,
+		dial:      dial,
+	}
+}
+
+// Get returns a connection to the current master, errors resolving the
+// master are reported by the connection.
+func (s *Sentinel) Get() redis.Conn {
+	conn, err := s.GetContext(context.Background())
+	if err != nil {
+		return errorConn{err}
+	}
+	return conn
+}
+
+// GetContext returns a connection to the current master, honoring ctx.
+func (s *Sentinel) GetContext(ctx context.Context) (redis.Conn, error) {
+	addr, pool, err := s.master()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pool.GetContext(ctx)
+	if err != nil {
+		s.failed(addr)
+		return nil, err
+	}
+	return &sentinelConn{Conn: conn, sentinel: s, addr: addr}, nil
+}
+
+// MasterAddr returns the address of the current master.
+func (s *Sentinel) MasterAddr() (string, error) {
+	addr, _, err := s.master()
+	return addr, err
+}
+
+func (s *Sentinel) master() (string, *redis.Pool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.pool != nil {
+		return s.addr, s.pool, nil
+	}
+
+	addr, err := s.resolve()
+	if err != nil {
+		return "", nil, err
+	}
+	s.addr = addr
+	s.pool = &redis.Pool{
+		Dial:        func() (redis.Conn, error) { return s.dial(addr) },
+		MaxIdle:     8,
+		IdleTimeout: 4 * time.Minute,
+	}
+	return s.addr, s.pool, nil
+}
+
+// resolve asks the sentinels for the address of the master.
+func (s *Sentinel) resolve() (string, error) {
+	err := errors.New("redisgraph: no sentinel configured")
+	for _, addr := range s.sentinels {
+		var master string
+		if master, err = s.ask(addr); err == nil {
+			return master, nil
+		}
+	}
+	return "", fmt.Errorf("redisgraph: resolving master of %q: %v", s.service, err)
+}
+
+func (s *Sentinel) ask(addr string) (string, error) {
+	conn, err := s.dial(addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	r, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", s.service))
+	if err == redis.ErrNil {
+		return "", fmt.Errorf("sentinel %s does not monitor %q", addr, s.service)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(r) != 2 {
+		return "", newParseError("malformed master address", r, nil)
+	}
+	master := net.JoinHostPort(r[0], r[1])
+	if err := s.checkRole(master); err != nil {
+		return "", err
+	}
+	return master, nil
+}
+
+// checkRole verifies that the node at addr is a master, as a sentinel may
+// report a demoted node until it notices the failover.
+func (s *Sentinel) checkRole(addr string) error {
+	conn, err := s.dial(addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+	if len(r) == 0 {
+		return newParseError("malformed role", r, nil)
+	}
+	role, err := redis.String(r[0], nil)
+	if err != nil {
+		return newParseError("malformed role", r, err)
+	}
+	if role != "master" {
+		return fmt.Errorf("%s reports role %s rather than master", addr, role)
+	}
+	return nil
+}
+
+// failed discards the master at addr, which is resolved again by the next
+// connection.
+func (s *Sentinel) failed(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.pool != nil && s.addr == addr {
+		s.pool.Close()
+		s.pool = nil
+		s.addr = ""
+	}
+}
+
+// Close closes the connections to the master.
+func (s *Sentinel) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.pool == nil {
+		return nil
+	}
+	err := s.pool.Close()
+	s.pool = nil
+	s.addr = ""
+	return err
+}
+
+// sentinelConn is a connection to the master which discards it upon
+// failures suggesting a failover.
+type sentinelConn struct {
+	redis.Conn
+	sentinel *Sentinel
+	addr     string
+}
+
+// check inspects the outcome of a command.
+func (c *sentinelConn) check(r interface{}, err error) (interface{}, error) {
+	switch e := err.(type) {
+	case nil:
+	case redis.Error:
+		if strings.HasPrefix(string(e), "READONLY") {
+			c.sentinel.failed(c.addr)
+			return r, &ReadOnlyError{Addr: c.addr, Err: e}
+		}
+	default:
+		if err != context.Canceled && err != context.DeadlineExceeded {
+			c.sentinel.failed(c.addr)
+		}
+	}
+	return r, err
+}
+
+func (c *sentinelConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.check(c.Conn.Do(cmd, args...))
+}
+
+func (c *sentinelConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return c.check(doContext(ctx, c.Conn, cmd, args...))
+}
+
+func (c *sentinelConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.check(redis.DoWithTimeout(c.Conn, timeout, cmd, args...))
+}
+
+func (c *sentinelConn) Receive() (interface{}, error) {
+	return c.check(c.Conn.Receive())
+}
+
+func (c *sentinelConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return c.check(receiveContext(ctx, c.Conn))
+}
+
+func (c *sentinelConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.check(redis.ReceiveWithTimeout(c.Conn, timeout))
+}
+
+// errorConn is a connection which could not be established.
+type errorConn struct{ err error }
+
+func (c errorConn) Close() error                                   { return nil }
+func (c errorConn) Err() error                                     { return c.err }
+func (c errorConn) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c errorConn) Send(string, ...interface{}) error              { return c.err }
+func (c errorConn) Flush() error                                   { return c.err }
+func (c errorConn) Receive() (interface{}, error)                  { return nil, c.err }
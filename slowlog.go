@@ -0,0 +1,278 @@
+package redisgraph
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// SlowLogEntry is a single entry of a graph's slow log.
+type SlowLogEntry struct {
+	Timestamp time.Time     // Time the command was issued at.
+	Command   string        // Command issued, e.g. GRAPH.QUERY.
+	Query     string        // Query text, including any CYPHER parameters header.
+	Duration  time.Duration // Time the command took to execute.
+}
+
+// SlowLogGroup aggregates slow log entries sharing the same normalized query.
+type SlowLogGroup struct {
+	Query   string         // Normalized query, see NormalizeQuery.
+	Count   int            // Number of entries in the group.
+	Total   time.Duration  // Sum of the entries' durations.
+	Max     time.Duration  // Longest duration within the group.
+	Last    time.Time      // Timestamp of the most recent entry.
+	Entries []SlowLogEntry // The grouped entries.
+}
+
+// Mean returns the average duration of the group's entries.
+func (sg SlowLogGroup) Mean() time.Duration {
+	if sg.Count == 0 {
+		return 0
+	}
+	return sg.Total / time.Duration(sg.Count)
+}
+
+// SlowLog retrieves the graph's slow log, which holds the slowest queries
+// recently issued against the graph.
+func (g *Graph) SlowLog() ([]SlowLogEntry, error) {
+	return g.SlowLogContext(context.Background())
+}
+
+// SlowLogContext retrieves the graph's slow log, honoring ctx.
+func (g *Graph) SlowLogContext(ctx context.Context) ([]SlowLogEntry, error) {
+	r, err := g.do(ctx, "GRAPH.SLOWLOG", g.Id)
+	if err != nil {
+		return nil, err
+	}
+	return parseSlowLog(r)
+}
+
+// ResetSlowLog clears the graph's slow log.
+func (g *Graph) ResetSlowLog() error {
+	return g.ResetSlowLogContext(context.Background())
+}
+
+// ResetSlowLogContext clears the graph's slow log, honoring ctx.
+func (g *Graph) ResetSlowLogContext(ctx context.Context) error {
+	_, err := g.do(ctx, "GRAPH.SLOWLOG", g.Id, "RESET")
+	return err
+}
+
+func parseSlowLog(reply interface{}) ([]SlowLogEntry, error) {
+	// [[timestamp, command, query, duration] X N]
+	rawEntries, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, newParseError("malformed slow log", reply, err)
+	}
+
+	entries := make([]SlowLogEntry, len(rawEntries))
+	for i, rawEntry := range rawEntries {
+		fields, err := redis.Strings(rawEntry, nil)
+		if err != nil || len(fields) != 4 {
+			return nil, newParseError("malformed slow log entry", rawEntry, err)
+		}
+		ts, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, newParseError("malformed slow log timestamp", rawEntry, err)
+		}
+		ms, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, newParseError("malformed slow log duration", rawEntry, err)
+		}
+
+		entries[i] = SlowLogEntry{
+			Timestamp: time.Unix(ts, 0),
+			Command:   fields[1],
+			Query:     fields[2],
+			Duration:  time.Duration(ms * float64(time.Millisecond)),
+		}
+	}
+	return entries, nil
+}
+
+// GroupSlowLog groups entries by their normalized query, groups are ordered
+// by their total duration, longest first.
+func GroupSlowLog(entries []SlowLogEntry) []SlowLogGroup {
+	index := make(map[string]int)
+	var groups []SlowLogGroup
+
+	for _, e := range entries {
+		q := NormalizeQuery(e.Query)
+		i, ok := index[q]
+		if !ok {
+			i = len(groups)
+			index[q] = i
+			groups = append(groups, SlowLogGroup{Query: q})
+		}
+
+		sg := &groups[i]
+		sg.Count++
+		sg.Total += e.Duration
+		if e.Duration > sg.Max {
+			sg.Max = e.Duration
+		}
+		if e.Timestamp.After(sg.Last) {
+			sg.Last = e.Timestamp
+		}
+		sg.Entries = append(sg.Entries, e)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Total > groups[j].Total
+	})
+	return groups
+}
+
+var (
+	literalList  = regexp.MustCompile(`\[\s*\?(\s*,\s*\?)*\s*\]`)
+	paramsHeader = regexp.MustCompile(`(?i)^CYPHER\s+`)
+	paramAssign  = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*`)
+)
+
+// NormalizeQuery reduces a query to its shape so that queries differing only
+// by their literal values compare equal: the CYPHER parameters header is
+// dropped, string and numeric literals are replaced by ?, lists of literals
+// are collapsed into [?] and whitespace is collapsed.
+func NormalizeQuery(q string) string {
+	var sb strings.Builder
+	space := false
+
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+
+		case c == '\'' || c == '"':
+			// String literal, honoring backslash escapes.
+			j := i + 1
+			for j < len(q) && q[j] != c {
+				if q[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			writeToken(&sb, "?", &space)
+			i = j + 1
+
+		case c == '`':
+			// Quoted identifiers are kept as is.
+			end := len(q)
+			if j := strings.IndexByte(q[i+1:], '`'); j >= 0 {
+				end = i + j + 2
+			}
+			writeToken(&sb, q[i:end], &space)
+			i = end
+
+		case isDigit(c) && (i == 0 || !isIdentChar(q[i-1])):
+			j := i
+			for j < len(q) && isDigit(q[j]) {
+				j++
+			}
+			// Fraction, but not a range such as 1..3.
+			if j+1 < len(q) && q[j] == '.' && isDigit(q[j+1]) {
+				j++
+				for j < len(q) && isDigit(q[j]) {
+					j++
+				}
+			}
+			// Exponent.
+			if j < len(q) && (q[j] == 'e' || q[j] == 'E') {
+				k := j + 1
+				if k < len(q) && q[k] == '-' {
+					k++
+				}
+				if k < len(q) && isDigit(q[k]) {
+					for k < len(q) && isDigit(q[k]) {
+						k++
+					}
+					j = k
+				}
+			}
+			writeToken(&sb, "?", &space)
+			i = j
+
+		default:
+			writeToken(&sb, string(c), &space)
+			i++
+		}
+	}
+
+	s := literalList.ReplaceAllString(sb.String(), "[?]")
+	return stripParamsHeader(s)
+}
+
+func writeToken(sb *strings.Builder, token string, space *bool) {
+	if *space && sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	*space = false
+	sb.WriteString(token)
+}
+
+// stripParamsHeader removes a leading "CYPHER name=value ..." header from a
+// query whose literals have already been replaced.
+func stripParamsHeader(q string) string {
+	loc := paramsHeader.FindStringIndex(q)
+	if loc == nil {
+		return q
+	}
+
+	rest := q[loc[1]:]
+	for {
+		m := paramAssign.FindStringIndex(rest)
+		if m == nil {
+			return strings.TrimLeft(rest, " ")
+		}
+		end := valueEnd(rest[m[1]:])
+		if end < 0 {
+			// Not a parameter assignment after all, keep the query as is.
+			return q
+		}
+		rest = strings.TrimLeft(rest[m[1]+end:], " ")
+	}
+}
+
+// valueEnd returns the length of the value at the start of s, a literal
+// placeholder, keyword or bracketed list or map, or -1 if there is none.
+func valueEnd(s string) int {
+	if s == "" {
+		return -1
+	}
+	switch s[0] {
+	case '[', '{':
+		depth := 0
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return -1
+	}
+	end := strings.IndexByte(s, ' ')
+	if end < 0 {
+		end = len(s)
+	}
+	return end
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,199 @@
+package redisgraph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrTxAborted is returned by Tx.Exec when a watched key was modified before
+// the transaction executed, none of its commands have been applied then.
+var ErrTxAborted = errors.New("redisgraph: transaction aborted, a watched key was modified")
+
+// ErrTxDone is returned by operations on a transaction which has already
+// been executed or discarded.
+var ErrTxDone = errors.New("redisgraph: transaction has already been executed or discarded")
+
+// Tx queues graph queries and plain Redis commands to be applied atomically
+// using MULTI/EXEC. A transaction holds on to a single connection from the
+// moment it begins until it is executed or discarded, for graphs created with
+// GraphNew the graph can not be used for anything else meanwhile. Graphs
+// created with GraphNewWithExecutor do not support transactions, beginning
+// one fails with ErrConnRequired. A Tx is not safe for concurrent use.
+type Tx struct {
+	graph   *Graph
+	ctx     context.Context
+	conn    redis.Conn
+	release func()
+	watched bool
+	queued  []txCommand
+	done    bool
+}
+
+// txCommand is a queued graph query, or plain command when query is nil.
+type txCommand struct {
+	query *pipelineQuery
+	cmd   string
+	args  []interface{}
+}
+
+// TxResult is the outcome of a single command of a transaction.
+type TxResult struct {
+	Result *QueryResult // Set for graph queries.
+	Reply  interface{}  // Raw reply, set for plain commands.
+	Err    error        // Set when the command failed.
+}
+
+// Begin starts a new transaction.
+func (g *Graph) Begin() (*Tx, error) {
+	return g.BeginContext(context.Background())
+}
+
+// BeginContext starts a new transaction, ctx applies to every command issued
+// by the transaction.
+func (g *Graph) BeginContext(ctx context.Context) (*Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	conn, release, err := g.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{graph: g, ctx: ctx, conn: conn, release: release}, nil
+}
+
+// Watch watches keys, e.g. the graph's own Id, making the transaction abort
+// with ErrTxAborted should any of them be modified before it executes.
+func (tx *Tx) Watch(keys ...string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	if _, err := doContext(tx.ctx, tx.conn, "WATCH", args...); err != nil {
+		return err
+	}
+	tx.watched = true
+	return nil
+}
+
+// Do issues a command right away, outside of the transaction, e.g. to read
+// the value of a watched key.
+func (tx *Tx) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	return doContext(tx.ctx, tx.conn, cmd, args...)
+}
+
+// Query queues a query, both params and options may be nil.
+func (tx *Tx) Query(q string, params map[string]interface{}, options *QueryOptions) *Tx {
+	tx.queued = append(tx.queued, txCommand{query: &pipelineQuery{"GRAPH.QUERY", q, params, options}})
+	return tx
+}
+
+// ROQuery queues a read only query, both params and options may be nil.
+func (tx *Tx) ROQuery(q string, params map[string]interface{}, options *QueryOptions) *Tx {
+	tx.queued = append(tx.queued, txCommand{query: &pipelineQuery{"GRAPH.RO_QUERY", q, params, options}})
+	return tx
+}
+
+// Command queues a plain Redis command, e.g. SET.
+func (tx *Tx) Command(cmd string, args ...interface{}) *Tx {
+	tx.queued = append(tx.queued, txCommand{cmd: cmd, args: args})
+	return tx
+}
+
+// Exec executes the queued commands atomically and returns their results, in
+// the order the commands were queued. ErrTxAborted is returned when a watched
+// key has been modified. A command failing at run-time does not roll back the
+// others, its error is reported by its own result.
+func (tx *Tx) Exec() ([]TxResult, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+
+	// Build every command up front, so that nothing is applied unless all
+	// of them are valid.
+	cmds := make([]txCommand, len(tx.queued))
+	for i, c := range tx.queued {
+		if c.query != nil {
+			args, err := tx.graph.queryArgs(tx.ctx, c.query.q, c.query.params, c.query.options)
+			if err != nil {
+				tx.Discard()
+				return nil, err
+			}
+			c.cmd, c.args = c.query.cmd, args
+		}
+		cmds[i] = c
+	}
+
+	r, err := tx.exec(cmds)
+	// Parsing may issue procedure calls of its own, release the connection first.
+	tx.finish()
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrTxAborted
+	}
+	replies, err := redis.Values(r, nil)
+	if err != nil || len(replies) != len(cmds) {
+		return nil, newParseError("malformed transaction reply", r, err)
+	}
+
+	results := make([]TxResult, len(cmds))
+	for i, reply := range replies {
+		if e, ok := reply.(redis.Error); ok {
+			results[i].Err = e
+		} else if cmds[i].query != nil {
+			results[i].Result, results[i].Err = queryResultNew(tx.ctx, tx.graph, reply, cmds[i].query.options)
+		} else {
+			results[i].Reply = reply
+		}
+	}
+	for i, c := range cmds {
+		if results[i].Err == nil && isWriteCommand(c.cmd, c.args) {
+			tx.graph.wrote()
+			break
+		}
+	}
+	return results, nil
+}
+
+// exec issues MULTI, the queued commands and EXEC, returning EXEC's reply.
+func (tx *Tx) exec(cmds []txCommand) (interface{}, error) {
+	if err := tx.conn.Send("MULTI"); err != nil {
+		return nil, err
+	}
+	for _, c := range cmds {
+		if err := tx.conn.Send(c.cmd, c.args...); err != nil {
+			return nil, err
+		}
+	}
+	// Replies to MULTI and the queued commands are consumed along the way,
+	// failing to queue a command aborts the transaction.
+	return doContext(tx.ctx, tx.conn, "EXEC")
+}
+
+// Discard abandons the transaction, releasing its connection.
+func (tx *Tx) Discard() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	var err error
+	if tx.watched {
+		_, err = tx.conn.Do("UNWATCH")
+	}
+	tx.finish()
+	return err
+}
+
+func (tx *Tx) finish() {
+	tx.done = true
+	tx.queued = nil
+	tx.release()
+}
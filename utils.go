@@ -3,67 +3,333 @@ package redisgraph
 import (
 	"crypto/rand"
 	"fmt"
-	"strings"
+	"math"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-// go array to string is [1 2 3] for [1, 2, 3] array
-// cypher expects comma separated array
-func arrayToString(arr []interface{}) string {
-	var arrayLength = len(arr)
-	strArray := []string{}
-	for i := 0; i < arrayLength; i++ {
-		strArray = append(strArray, ToString(arr[i]))
-	}
-	return "[" + strings.Join(strArray, ",") + "]"
+// CypherValue is implemented by types which know how to encode themselves as
+// Cypher literals, it takes precedence over the built-in encoding rules.
+type CypherValue interface {
+	EncodeCypher() (string, error)
+}
+
+// UnsupportedValueError is returned when a Go value has no Cypher representation.
+type UnsupportedValueError struct {
+	Value interface{}
+	Msg   string
 }
 
-// go array to string is [1 2 3] for [1, 2, 3] array
-// cypher expects comma separated array
-func strArrayToString(arr []string) string {
-	var arrayLength = len(arr)
-	strArray := []string{}
-	for i := 0; i < arrayLength; i++ {
-		strArray = append(strArray, ToString(arr[i]))
+func (e *UnsupportedValueError) Error() string {
+	if e.Msg != "" {
+		return fmt.Sprintf("redisgraph: can not encode %T as a Cypher value: %s", e.Value, e.Msg)
 	}
-	return "[" + strings.Join(strArray, ",") + "]"
+	return fmt.Sprintf("redisgraph: can not encode %T as a Cypher value", e.Value)
 }
 
-func mapToString(data map[string]interface{}) string {
-	pairsArray := []string{}
-	for k, v := range data {
-		pairsArray = append(pairsArray, k + ": " + ToString(v))
+var cypherValueType = reflect.TypeOf((*CypherValue)(nil)).Elem()
+
+// EncodeValue encodes v as a Cypher literal.
+//
+// nil, booleans, strings, []byte (as a string), every integer and float type,
+// slices, arrays, maps keyed by strings, structs (as maps, keyed by their
+// `redisgraph` tag or field name) and pointers to any of these are supported,
+// as are types implementing CypherValue, such as Point and Vector32.
+// time.Time values are encoded as local date times, in UTC, and time.Duration
+// values as durations; as the server keeps whole seconds only, values with
+// a fractional second are rejected with an *UnsupportedValueError. Map keys are emitted in
+// sorted order so equal values always encode identically.
+func EncodeValue(v interface{}) (string, error) {
+	var sb strings.Builder
+	if err := encodeValue(&sb, reflect.ValueOf(v)); err != nil {
+		return "", err
 	}
-	return "{" + strings.Join(pairsArray, ",") + "}"
+	return sb.String(), nil
 }
 
-func ToString(i interface{}) string {
-	if(i == nil) {
-		return "null"
-	}
-
-	switch i.(type) {
-	case string:
-		s := i.(string)
-		return strconv.Quote(s)
-	case int:
-		return strconv.Itoa(i.(int))
-	case float64:
-		return strconv.FormatFloat(i.(float64), 'f', -1, 64)
-	case bool:
-		return strconv.FormatBool(i.(bool))
-	case []interface{}:
-		arr := i.([]interface{})
-		return arrayToString(arr)
-	case map[string]interface{}:
-		data := i.(map[string]interface{})
-		return mapToString(data)
-	case []string:
-		arr := i.([]string)
-		return strArrayToString(arr)
+func encodeValue(sb *strings.Builder, v reflect.Value) error {
+	if !v.IsValid() {
+		sb.WriteString("null")
+		return nil
+	}
+
+	if v.Type().Implements(cypherValueType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			sb.WriteString("null")
+			return nil
+		}
+		s, err := v.Interface().(CypherValue).EncodeCypher()
+		if err != nil {
+			return err
+		}
+		sb.WriteString(s)
+		return nil
+	}
+	if s, ok, err := encodeTemporal(v); ok {
+		if err != nil {
+			return err
+		}
+		sb.WriteString(s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			sb.WriteString("null")
+			return nil
+		}
+		return encodeValue(sb, v.Elem())
+
+	case reflect.Bool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+
+	case reflect.String:
+		sb.WriteString(quoteString(v.String()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sb.WriteString(strconv.FormatInt(v.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > math.MaxInt64 {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: "integer overflows int64"}
+		}
+		sb.WriteString(strconv.FormatUint(u, 10))
+
+	case reflect.Float32, reflect.Float64:
+		s, err := formatFloat(v.Float(), v.Type().Bits())
+		if err != nil {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: err.Error()}
+		}
+		sb.WriteString(s)
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			sb.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte slices are sent as strings.
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			if !utf8.Valid(b) {
+				return &UnsupportedValueError{Value: v.Interface(), Msg: "bytes are not valid UTF-8"}
+			}
+			sb.WriteString(quoteString(string(b)))
+			return nil
+		}
+		sb.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			if err := encodeValue(sb, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("]")
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: "map keys must be strings"}
+		}
+		if v.IsNil() {
+			sb.WriteString("null")
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			keys = append(keys, k)
+			values[k] = iter.Value()
+		}
+		sort.Strings(keys)
+		return encodeMap(sb, keys, values)
+
+	case reflect.Struct:
+		if isEntityType(v.Type()) {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: "graph entities can not be used as values"}
+		}
+		values := make(map[string]reflect.Value, v.NumField())
+		collectStructFields(v, values)
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		if len(keys) == 0 {
+			return &UnsupportedValueError{Value: v.Interface(), Msg: "struct has no exported fields"}
+		}
+		sort.Strings(keys)
+		return encodeMap(sb, keys, values)
+
 	default:
-		panic("Unrecognized type to convert to string")
+		return &UnsupportedValueError{Value: v.Interface()}
+	}
+
+	return nil
+}
+
+// collectStructFields gathers the exported fields of struct v keyed by their
+// `redisgraph` tag or field name, descending into embedded structs.
+func collectStructFields(v reflect.Value, values map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("redisgraph")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			collectStructFields(v.Field(i), values)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		values[name] = v.Field(i)
+	}
+}
+
+func encodeMap(sb *strings.Builder, keys []string, values map[string]reflect.Value) error {
+	sb.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(quoteIdentifier(k))
+		sb.WriteString(": ")
+		if err := encodeValue(sb, values[k]); err != nil {
+			return err
+		}
+	}
+	sb.WriteString("}")
+	return nil
+}
+
+// formatFloat formats f so that it is always parsed back as a float,
+// e.g. 1.0 rather than 1.
+func formatFloat(f float64, bits int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%v has no Cypher literal", f)
 	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if strings.Contains(s, "e") {
+		// Cypher does not accept an explicit positive exponent sign.
+		s = strings.Replace(s, "e+", "e", 1)
+		if !strings.Contains(s[:strings.Index(s, "e")], ".") {
+			s = strings.Replace(s, "e", ".0e", 1)
+		}
+	} else if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s, nil
+}
+
+// quoteString returns s as a double quoted Cypher string literal.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isIdentifier reports whether s can be used as an unquoted Cypher identifier.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// quoteIdentifier returns s, backquoted if it is not a valid identifier.
+func quoteIdentifier(s string) string {
+	if isIdentifier(s) {
+		return s
+	}
+	return "`" + strings.Replace(s, "`", "``", -1) + "`"
+}
+
+// formatProperties encodes an entity's properties, using encode, as comma
+// separated key:value pairs, ordered by key.
+func formatProperties(properties map[string]interface{}, encode func(interface{}) (string, error)) (string, error) {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	p := make([]string, len(keys))
+	for i, k := range keys {
+		v, err := encode(properties[k])
+		if err != nil {
+			return "", err
+		}
+		p[i] = quoteIdentifier(k) + ":" + v
+	}
+	return strings.Join(p, ","), nil
+}
+
+// encodeBestEffort encodes v as EncodeValue does, falling back to the
+// formatting of fmt.Sprint for values which can not be encoded. It backs
+// deprecated functions which have no way of reporting errors, it never fails.
+func encodeBestEffort(v interface{}) (string, error) {
+	s, err := EncodeValue(v)
+	if err != nil {
+		return fmt.Sprint(v), nil
+	}
+	return s, nil
+}
+
+// ToString encodes i as a Cypher literal, see EncodeValue.
+//
+// Deprecated: values which can not be encoded are formatted by fmt.Sprint
+// instead, yielding invalid Cypher, use EncodeValue.
+func ToString(i interface{}) string {
+	s, _ := encodeBestEffort(i)
+	return s
 }
 
 // https://medium.com/@kpbird/golang-generate-fixed-size-random-string-dd6dbd5e63c0
@@ -91,10 +357,52 @@ func RandomString(n int) string {
 	return string(output)
 }
 
-func BuildParamsHeader(params map[string]interface{}) (string) {
-	header := "CYPHER "
-	for key, value := range params {
-		header += fmt.Sprintf("%s=%v ", key, ToString(value))
+// BuildParamsHeader encodes params as a CYPHER query prefix, see
+// EncodeParamsHeader.
+//
+// Deprecated: parameter names are not validated and values which can not be
+// encoded are formatted by fmt.Sprint instead, yielding invalid Cypher, use
+// EncodeParamsHeader.
+func BuildParamsHeader(params map[string]interface{}) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("CYPHER ")
+	for _, key := range keys {
+		v, _ := encodeBestEffort(params[key])
+		sb.WriteString(key)
+		sb.WriteString("=")
+		sb.WriteString(v)
+		sb.WriteString(" ")
+	}
+	return sb.String()
+}
+
+// EncodeParamsHeader encodes params as a CYPHER query prefix, parameters are
+// emitted in sorted order so identical queries share the server's query cache.
+func EncodeParamsHeader(params map[string]interface{}) (string, error) {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if !isIdentifier(key) {
+			return "", fmt.Errorf("redisgraph: invalid parameter name %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("CYPHER ")
+	for _, key := range keys {
+		sb.WriteString(key)
+		sb.WriteString("=")
+		if err := encodeValue(&sb, reflect.ValueOf(params[key])); err != nil {
+			return "", err
+		}
+		sb.WriteString(" ")
 	}
-	return header
+	return sb.String(), nil
 }
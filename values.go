@@ -0,0 +1,79 @@
+package redisgraph
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Point is a geographical location, as created by Cypher's point function.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// EncodeCypher encodes p as a call to point.
+func (p Point) EncodeCypher() (string, error) {
+	lat, err := formatFloat(p.Latitude, 64)
+	if err != nil {
+		return "", &UnsupportedValueError{Value: p, Msg: err.Error()}
+	}
+	lon, err := formatFloat(p.Longitude, 64)
+	if err != nil {
+		return "", &UnsupportedValueError{Value: p, Msg: err.Error()}
+	}
+	return fmt.Sprintf("point({latitude: %s, longitude: %s})", lat, lon), nil
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("point({latitude: %v, longitude: %v})", p.Latitude, p.Longitude)
+}
+
+// Vector32 is a vector of 32 bit floats, as created by Cypher's vecf32 function.
+type Vector32 []float32
+
+// EncodeCypher encodes v as a call to vecf32.
+func (v Vector32) EncodeCypher() (string, error) {
+	elems := make([]string, len(v))
+	for i, f := range v {
+		s, err := formatFloat(float64(f), 32)
+		if err != nil {
+			return "", &UnsupportedValueError{Value: v, Msg: err.Error()}
+		}
+		elems[i] = s
+	}
+	return "vecf32([" + strings.Join(elems, ",") + "])", nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// localDateTimeLayout is the layout of the strings accepted by localdatetime.
+const localDateTimeLayout = "2006-01-02T15:04:05"
+
+// encodeTemporal encodes time.Time values as local date times and
+// time.Duration values as durations. Times are converted to UTC first, as
+// localdatetime holds no time zone, and come back from queries in UTC.
+// RedisGraph stores temporal values with a precision of one second, values
+// with a fractional second are rejected rather than silently truncated.
+// ok is false for values of any other type.
+func encodeTemporal(v reflect.Value) (s string, ok bool, err error) {
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time).UTC()
+		if t.Nanosecond() != 0 {
+			return "", true, &UnsupportedValueError{Value: v.Interface(), Msg: "fractional seconds are not supported"}
+		}
+		return fmt.Sprintf("localdatetime(%s)", quoteString(t.Format(localDateTimeLayout))), true, nil
+	case durationType:
+		d := time.Duration(v.Int())
+		if d%time.Second != 0 {
+			return "", true, &UnsupportedValueError{Value: v.Interface(), Msg: "fractional seconds are not supported"}
+		}
+		return fmt.Sprintf("duration({seconds: %d})", int64(d/time.Second)), true, nil
+	}
+	return "", false, nil
+}